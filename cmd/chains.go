@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"strings"
 
 	"github.com/cosmos/relayer/relayer"
 	"github.com/spf13/cobra"
@@ -97,14 +98,14 @@ func chainsShowCmd() *cobra.Command {
 				return nil
 			default:
 				fmt.Printf(`chain-id:        %s
-rpc-addr:        %s
+rpc-addrs:       %s
 trusting-period: %s
 default-denom:   %s
 gas:             %d
 gas-prices:      %s
 key:             %s
 account-prefix:  %s
-`, c.ChainID, c.RPCAddr, c.TrustingPeriod, c.DefaultDenom, c.Gas, c.GasPrices, c.Key, c.AccountPrefix)
+`, c.ChainID, strings.Join(c.GetRPCAddrs(), ","), c.TrustingPeriod, c.DefaultDenom, c.Gas, c.GasPrices, c.Key, c.AccountPrefix)
 				return nil
 			}
 		},
@@ -198,7 +199,7 @@ func chainsListCmd() *cobra.Command {
 						key = "✔"
 					}
 
-					coins, err := c.QueryBalance(c.Key)
+					coins, err := c.QueryBalance(cmd.Context(), c.Key)
 					if err == nil && !coins.Empty() {
 						bal = "✔"
 					}
@@ -300,7 +301,7 @@ func filesAdd(dir string) (cfg *Config, err error) {
 			fmt.Printf("failed to unmarshal file %s, skipping...\n", pth)
 			continue
 		}
-		if c.ChainID == "" && c.Key == "" && c.RPCAddr == "" {
+		if c.ChainID == "" && c.Key == "" && len(c.GetRPCAddrs()) == 0 {
 			p := &relayer.Path{}
 			if err = json.Unmarshal(byt, p); err == nil {
 				fmt.Printf("%s is a path file, try adding it with 'rly pth add -f %s'...\n", f.Name(), pth)
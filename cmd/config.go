@@ -177,7 +177,7 @@ func cfgFilesAdd(dir string) (cfg *Config, err error) {
 			continue
 		}
 
-		if c.ChainID == "" && c.Key == "" && c.RPCAddr == "" {
+		if c.ChainID == "" && c.Key == "" && len(c.GetRPCAddrs()) == 0 {
 			p := &relayer.Path{}
 			if err = json.Unmarshal(byt, p); err != nil {
 				fmt.Printf("failed to unmarshal file %s, skipping...\n", pth)
@@ -240,6 +240,9 @@ func (c *Config) ChainsFromPath(path string) (map[string]*relayer.Chain, string,
 		return nil, "", "", err
 	}
 
+	chains[src].SetMaxClockDrift(pth.GetMaxClockDrift())
+	chains[dst].SetMaxClockDrift(pth.GetMaxClockDrift())
+
 	return chains, src, dst, nil
 }
 
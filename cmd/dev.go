@@ -77,10 +77,7 @@ func listenCmd() *cobra.Command {
 				return fmt.Errorf("Must output block and/or tx")
 			}
 
-			done := c.ListenRPCEmitJSON(tx, block, data)
-
-			trapSignal(done)
-
+			c.ListenRPCEmitJSON(cmd.Context(), tx, block, data)
 			return nil
 		},
 	}
@@ -193,12 +190,12 @@ func rlyService() *cobra.Command {
 
 			// ensure that balances aren't == nil
 			var srcBal, dstBal sdk.Coins
-			if srcBal, err = chains[src].QueryBalance(chains[src].Key); err != nil {
+			if srcBal, err = chains[src].QueryBalance(cmd.Context(), chains[src].Key); err != nil {
 				return err
 			} else if srcBal.AmountOf(chains[src].DefaultDenom).IsZero() {
 				return fmt.Errorf("no balance on %s, ensure %s has a balance before continuing setup", src, chains[src].MustGetAddress())
 			}
-			if dstBal, err = chains[dst].QueryBalance(chains[dst].Key); err != nil {
+			if dstBal, err = chains[dst].QueryBalance(cmd.Context(), chains[dst].Key); err != nil {
 				return err
 			} else if dstBal.AmountOf(chains[dst].DefaultDenom).IsZero() {
 				return fmt.Errorf("no balance on %s, ensure %s has a balance before continuing setup", dst, chains[dst].MustGetAddress())
@@ -1,29 +1,37 @@
 package cmd
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/relayer/relayer"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var (
-	flagHash       = "hash"
-	flagURL        = "url"
-	flagForce      = "force"
-	flagFlags      = "flags"
-	flagTimeout    = "timeout"
-	flagConfig     = "config"
-	flagJSON       = "json"
-	flagYAML       = "yaml"
-	flagFile       = "file"
-	flagPath       = "path"
-	flagListenAddr = "listen"
-	flagTx         = "no-tx"
-	flagBlock      = "no-block"
-	flagData       = "data"
-	flagOrder      = "unordered"
+	flagHash         = "hash"
+	flagURL          = "url"
+	flagForce        = "force"
+	flagFlags        = "flags"
+	flagTimeout      = "timeout"
+	flagConfig       = "config"
+	flagJSON         = "json"
+	flagYAML         = "yaml"
+	flagFile         = "file"
+	flagPath         = "path"
+	flagListenAddr   = "listen"
+	flagTx           = "no-tx"
+	flagBlock        = "no-block"
+	flagData         = "data"
+	flagOrder        = "unordered"
+	flagAll          = "all"
+	flagDeadline     = "deadline"
+	flagDryRun       = "dry-run"
+	flagMaxAttempts  = "max-attempts"
+	flagBackoff      = "backoff"
+	flagRestartDelay = "restart-delay"
 )
 
 func liteFlags(cmd *cobra.Command) *cobra.Command {
@@ -70,6 +78,14 @@ func orderFlag(cmd *cobra.Command) *cobra.Command {
 	return cmd
 }
 
+func allFlag(cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().BoolP(flagAll, "a", false, "run the relayer on all configured paths")
+	if err := viper.BindPFlag(flagAll, cmd.Flags().Lookup(flagAll)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
 func listenFlags(cmd *cobra.Command) *cobra.Command {
 	cmd.Flags().BoolP(flagTx, "t", false, "don't output transaction events")
 	cmd.Flags().BoolP(flagBlock, "b", false, "don't output block events")
@@ -148,6 +164,78 @@ func flagsFlag(cmd *cobra.Command) *cobra.Command {
 	return cmd
 }
 
+func dryRunFlag(cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().Bool(flagDryRun, false, "print the msgs that would be sent to each chain, with their estimated gas, instead of broadcasting them")
+	if err := viper.BindPFlag(flagDryRun, cmd.Flags().Lookup(flagDryRun)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+// setDryRun puts the chains in dry run mode if the --dry-run flag is set
+func setDryRun(cmd *cobra.Command, chains ...*relayer.Chain) (bool, error) {
+	dryRun, err := cmd.Flags().GetBool(flagDryRun)
+	if err != nil {
+		return false, err
+	}
+	for _, c := range chains {
+		c.SetDryRun(dryRun)
+	}
+	return dryRun, nil
+}
+
+func linkRetryFlags(cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().Uint(flagMaxAttempts, 3, "how many times each handshake step is tried before giving up")
+	cmd.Flags().String(flagBackoff, "5s", "delay before retrying a failed handshake step, doubled after each attempt")
+	if err := viper.BindPFlag(flagMaxAttempts, cmd.Flags().Lookup(flagMaxAttempts)); err != nil {
+		panic(err)
+	}
+	if err := viper.BindPFlag(flagBackoff, cmd.Flags().Lookup(flagBackoff)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+// getLinker returns a linker configured by the timeout and link retry flags
+func getLinker(cmd *cobra.Command) (*relayer.Linker, error) {
+	to, err := getTimeout(cmd)
+	if err != nil {
+		return nil, err
+	}
+	attempts, err := cmd.Flags().GetUint(flagMaxAttempts)
+	if err != nil {
+		return nil, err
+	}
+	if attempts == 0 {
+		return nil, fmt.Errorf("--%s must be at least 1", flagMaxAttempts)
+	}
+	backoff, err := cmd.Flags().GetString(flagBackoff)
+	if err != nil {
+		return nil, err
+	}
+	bo, err := time.ParseDuration(backoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse --%s: %w", flagBackoff, err)
+	}
+	return &relayer.Linker{Timeout: to, MaxAttempts: attempts, Backoff: bo}, nil
+}
+
+func restartDelayFlag(cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().String(flagRestartDelay, "10s", "delay before restarting a path that failed")
+	if err := viper.BindPFlag(flagRestartDelay, cmd.Flags().Lookup(flagRestartDelay)); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func getRestartDelay(cmd *cobra.Command) (time.Duration, error) {
+	delay, err := cmd.Flags().GetString(flagRestartDelay)
+	if err != nil {
+		return 0, err
+	}
+	return time.ParseDuration(delay)
+}
+
 func getTimeout(cmd *cobra.Command) (time.Duration, error) {
 	to, err := cmd.Flags().GetString(flagTimeout)
 	if err != nil {
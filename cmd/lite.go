@@ -88,7 +88,7 @@ func initLiteCmd() *cobra.Command {
 
 			switch {
 			case force: // force initialization from trusted node
-				_, err = chain.TrustNodeInitClient(db)
+				_, err = chain.TrustNodeInitClient(cmd.Context(), db)
 				if err != nil {
 					return err
 				}
@@ -191,7 +191,7 @@ func updateLiteCmd() *cobra.Command {
 				// think we should remove first two conditions here and just make
 				// updateLiteCmd only about updating the light client to latest header
 				// (i.e. not mix responsibilities).
-				_, err = chain.UpdateLiteWithHeader()
+				_, err = chain.UpdateLiteWithHeader(cmd.Context())
 				if err != nil {
 					return wrapIncorrectHeader(err)
 				}
@@ -42,7 +42,7 @@ func pathsFindCmd() *cobra.Command {
 		Short: "WIP: finds any existing paths between any configured chains and outputs them to stdout",
 		Args:  cobra.ExactArgs(0),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			paths, err := relayer.FindPaths(config.Chains)
+			paths, err := relayer.FindPaths(cmd.Context(), config.Chains)
 			if err != nil {
 				return err
 			}
@@ -91,7 +91,7 @@ func pathsGenCmd() *cobra.Command {
 				path.Dst.Order = "ORDERED"
 			}
 
-			srcClients, err := c[src].QueryClients(1, 1000)
+			srcClients, err := c[src].QueryClients(cmd.Context(), 1, 1000)
 			if err != nil {
 				return err
 			}
@@ -106,7 +106,7 @@ func pathsGenCmd() *cobra.Command {
 				}
 			}
 
-			dstClients, err := c[dst].QueryClients(1, 1000)
+			dstClients, err := c[dst].QueryClients(cmd.Context(), 1, 1000)
 			if err != nil {
 				return err
 			}
@@ -155,7 +155,7 @@ func pathsGenCmd() *cobra.Command {
 				return overWriteConfig(cmd, config)
 			}
 
-			srcConns, err := c[src].QueryConnections(1, 1000)
+			srcConns, err := c[src].QueryConnections(cmd.Context(), 1, 1000)
 			if err != nil {
 				return err
 			}
@@ -168,7 +168,7 @@ func pathsGenCmd() *cobra.Command {
 				}
 			}
 
-			dstConns, err := c[dst].QueryConnections(1, 1000)
+			dstConns, err := c[dst].QueryConnections(cmd.Context(), 1, 1000)
 			if err != nil {
 				return err
 			}
@@ -211,7 +211,7 @@ func pathsGenCmd() *cobra.Command {
 				return overWriteConfig(cmd, config)
 			}
 
-			srcChans, err := c[src].QueryChannels(1, 1000)
+			srcChans, err := c[src].QueryChannels(cmd.Context(), 1, 1000)
 			if err != nil {
 				return err
 			}
@@ -224,7 +224,7 @@ func pathsGenCmd() *cobra.Command {
 				}
 			}
 
-			dstChans, err := c[dst].QueryChannels(1, 1000)
+			dstChans, err := c[dst].QueryChannels(cmd.Context(), 1, 1000)
 			if err != nil {
 				return err
 			}
@@ -349,8 +349,8 @@ func pathsListCmd() *cobra.Command {
 						continue
 					}
 
-					srcCs, err := ch[src].QueryClientState()
-					dstCs, _ := ch[dst].QueryClientState()
+					srcCs, err := ch[src].QueryClientState(cmd.Context())
+					dstCs, _ := ch[dst].QueryClientState(cmd.Context())
 					if err == nil && srcCs != nil && dstCs != nil {
 						clients = "✔"
 					} else {
@@ -359,16 +359,16 @@ func pathsListCmd() *cobra.Command {
 						continue
 					}
 
-					srch, err := ch[src].QueryLatestHeight()
-					dsth, _ := ch[dst].QueryLatestHeight()
+					srch, err := ch[src].QueryLatestHeight(cmd.Context())
+					dsth, _ := ch[dst].QueryLatestHeight(cmd.Context())
 					if err != nil || srch == -1 || dsth == -1 {
 						printPath(i, k, pth, chains, clients, connection, channel)
 						i++
 						continue
 					}
 
-					srcConn, err := ch[src].QueryConnection(srch)
-					dstConn, _ := ch[dst].QueryConnection(dsth)
+					srcConn, err := ch[src].QueryConnection(cmd.Context(), srch)
+					dstConn, _ := ch[dst].QueryConnection(cmd.Context(), dsth)
 					if err == nil && srcConn.Connection.Connection.State.String() == "OPEN" && dstConn.Connection.Connection.State.String() == "OPEN" {
 						connection = "✔"
 					} else {
@@ -377,8 +377,8 @@ func pathsListCmd() *cobra.Command {
 						continue
 					}
 
-					srcChan, err := ch[src].QueryChannel(srch)
-					dstChan, _ := ch[dst].QueryChannel(dsth)
+					srcChan, err := ch[src].QueryChannel(cmd.Context(), srch)
+					dstChan, _ := ch[dst].QueryChannel(cmd.Context(), dsth)
 					if err == nil && srcChan.Channel.Channel.State.String() == "OPEN" && dstChan.Channel.Channel.State.String() == "OPEN" {
 						channel = "✔"
 					} else {
@@ -455,8 +455,8 @@ func pathsShowCmd() *cobra.Command {
 			src, dst := path.Src.ChainID, path.Dst.ChainID
 			ch, err := config.Chains.Gets(src, dst)
 			if err == nil {
-				srch, err = ch[src].QueryLatestHeight()
-				dsth, _ = ch[dst].QueryLatestHeight()
+				srch, err = ch[src].QueryLatestHeight(cmd.Context())
+				dsth, _ = ch[dst].QueryLatestHeight(cmd.Context())
 				if err == nil {
 					chains = true
 					_ = ch[src].SetPath(path.Src)
@@ -464,20 +464,20 @@ func pathsShowCmd() *cobra.Command {
 				}
 			}
 
-			srcCs, err := ch[src].QueryClientState()
-			dstCs, _ := ch[dst].QueryClientState()
+			srcCs, err := ch[src].QueryClientState(cmd.Context())
+			dstCs, _ := ch[dst].QueryClientState(cmd.Context())
 			if err == nil && srcCs != nil && dstCs != nil {
 				clients = true
 			}
 
-			srcConn, err := ch[src].QueryConnection(srch)
-			dstConn, _ := ch[dst].QueryConnection(dsth)
+			srcConn, err := ch[src].QueryConnection(cmd.Context(), srch)
+			dstConn, _ := ch[dst].QueryConnection(cmd.Context(), dsth)
 			if err == nil && srcConn.Connection.Connection.State.String() == "OPEN" && dstConn.Connection.Connection.State.String() == "OPEN" {
 				connection = true
 			}
 
-			srcChan, err := ch[src].QueryChannel(srch)
-			dstChan, _ := ch[dst].QueryChannel(dsth)
+			srcChan, err := ch[src].QueryChannel(cmd.Context(), srch)
+			dstChan, _ := ch[dst].QueryChannel(cmd.Context(), dsth)
 			if err == nil && srcChan.Channel.Channel.State.String() == "OPEN" && dstChan.Channel.Channel.State.String() == "OPEN" {
 				channel = true
 			}
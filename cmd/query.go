@@ -61,7 +61,7 @@ func queryTx() *cobra.Command {
 				return err
 			}
 
-			txs, err := chain.QueryTx(args[1])
+			txs, err := chain.QueryTx(cmd.Context(), args[1])
 			if err != nil {
 				return err
 			}
@@ -92,12 +92,12 @@ documents its respective events under 'cosmos-sdk/x/{module}/spec/xx_events.md'.
 				return err
 			}
 
-			h, err := chain.UpdateLiteWithHeader()
+			h, err := chain.UpdateLiteWithHeader(cmd.Context())
 			if err != nil {
 				return err
 			}
 
-			txs, err := chain.QueryTxs(h.GetHeight(), viper.GetInt(flags.FlagPage), viper.GetInt(flags.FlagLimit), events)
+			txs, err := chain.QueryTxs(cmd.Context(), h.GetHeight(), viper.GetInt(flags.FlagPage), viper.GetInt(flags.FlagLimit), events)
 			if err != nil {
 				return err
 			}
@@ -158,7 +158,7 @@ func queryBalanceCmd() *cobra.Command {
 				keyName = args[1]
 			}
 
-			coins, err := chain.QueryBalance(keyName)
+			coins, err := chain.QueryBalance(cmd.Context(), keyName)
 			if err != nil {
 				return err
 			}
@@ -197,7 +197,7 @@ func queryHeaderCmd() *cobra.Command {
 
 			switch len(args) {
 			case 1:
-				header, err = chain.QueryLatestHeader()
+				header, err = chain.QueryLatestHeader(cmd.Context())
 				if err != nil {
 					return err
 				}
@@ -209,7 +209,7 @@ func queryHeaderCmd() *cobra.Command {
 				}
 
 				if height == 0 {
-					height, err = chain.QueryLatestHeight()
+					height, err = chain.QueryLatestHeight(cmd.Context())
 					if err != nil {
 						return err
 					}
@@ -219,7 +219,7 @@ func queryHeaderCmd() *cobra.Command {
 					}
 				}
 
-				header, err = chain.QueryHeaderAtHeight(height)
+				header, err = chain.QueryHeaderAtHeight(cmd.Context(), height)
 				if err != nil {
 					return err
 				}
@@ -254,7 +254,7 @@ func queryNodeStateCmd() *cobra.Command {
 			var height int64
 			switch len(args) {
 			case 1:
-				height, err = chain.QueryLatestHeight()
+				height, err = chain.QueryLatestHeight(cmd.Context())
 				if err != nil {
 					return err
 				}
@@ -266,7 +266,7 @@ func queryNodeStateCmd() *cobra.Command {
 				}
 			}
 
-			csRes, err := chain.QueryConsensusState(height)
+			csRes, err := chain.QueryConsensusState(cmd.Context(), height)
 			if err != nil {
 				return err
 			}
@@ -294,7 +294,7 @@ func queryClientCmd() *cobra.Command {
 				return err
 			}
 
-			res, err := chain.QueryClientState()
+			res, err := chain.QueryClientState(cmd.Context())
 			if err != nil {
 				return err
 			}
@@ -318,7 +318,7 @@ func queryClientsCmd() *cobra.Command {
 				return err
 			}
 
-			res, err := chain.QueryClients(viper.GetInt(flags.FlagPage), viper.GetInt(flags.FlagLimit))
+			res, err := chain.QueryClients(cmd.Context(), viper.GetInt(flags.FlagPage), viper.GetInt(flags.FlagLimit))
 			if err != nil {
 				return err
 			}
@@ -342,7 +342,7 @@ func queryConnections() *cobra.Command {
 				return err
 			}
 
-			res, err := chain.QueryConnections(viper.GetInt(flags.FlagPage), viper.GetInt(flags.FlagLimit))
+			res, err := chain.QueryConnections(cmd.Context(), viper.GetInt(flags.FlagPage), viper.GetInt(flags.FlagLimit))
 			if err != nil {
 				return err
 			}
@@ -370,12 +370,12 @@ func queryConnectionsUsingClient() *cobra.Command {
 				return err
 			}
 
-			height, err := chain.QueryLatestHeight()
+			height, err := chain.QueryLatestHeight(cmd.Context())
 			if err != nil {
 				return err
 			}
 
-			res, err := chain.QueryConnectionsUsingClient(height)
+			res, err := chain.QueryConnectionsUsingClient(cmd.Context(), height)
 			if err != nil {
 				return err
 			}
@@ -403,12 +403,12 @@ func queryConnection() *cobra.Command {
 				return err
 			}
 
-			height, err := chain.QueryLatestHeight()
+			height, err := chain.QueryLatestHeight(cmd.Context())
 			if err != nil {
 				return err
 			}
 
-			res, err := chain.QueryConnection(height)
+			res, err := chain.QueryConnection(cmd.Context(), height)
 			if err != nil {
 				return err
 			}
@@ -435,7 +435,7 @@ func queryConnectionChannels() *cobra.Command {
 				return err
 			}
 
-			chans, err := chain.QueryConnectionChannels(args[1], viper.GetInt(flags.FlagPage), viper.GetInt(flags.FlagLimit))
+			chans, err := chain.QueryConnectionChannels(cmd.Context(), args[1], viper.GetInt(flags.FlagPage), viper.GetInt(flags.FlagLimit))
 			if err != nil {
 				return err
 			}
@@ -462,12 +462,12 @@ func queryChannel() *cobra.Command {
 				return err
 			}
 
-			height, err := chain.QueryLatestHeight()
+			height, err := chain.QueryLatestHeight(cmd.Context())
 			if err != nil {
 				return err
 			}
 
-			res, err := chain.QueryChannel(height)
+			res, err := chain.QueryChannel(cmd.Context(), height)
 			if err != nil {
 				return err
 			}
@@ -491,7 +491,7 @@ func queryChannels() *cobra.Command {
 				return err
 			}
 
-			res, err := chain.QueryChannels(viper.GetInt(flags.FlagPage), viper.GetInt(flags.FlagLimit))
+			res, err := chain.QueryChannels(cmd.Context(), viper.GetInt(flags.FlagPage), viper.GetInt(flags.FlagLimit))
 			if err != nil {
 				return err
 			}
@@ -518,12 +518,12 @@ func queryNextSeqRecv() *cobra.Command {
 				return err
 			}
 
-			height, err := chain.QueryLatestHeight()
+			height, err := chain.QueryLatestHeight(cmd.Context())
 			if err != nil {
 				return err
 			}
 
-			res, err := chain.QueryNextSeqRecv(height)
+			res, err := chain.QueryNextSeqRecv(cmd.Context(), height)
 			if err != nil {
 				return err
 			}
@@ -550,7 +550,7 @@ func queryPacketCommitment() *cobra.Command {
 				return err
 			}
 
-			height, err := chain.QueryLatestHeight()
+			height, err := chain.QueryLatestHeight(cmd.Context())
 			if err != nil {
 				return err
 			}
@@ -560,7 +560,7 @@ func queryPacketCommitment() *cobra.Command {
 				return err
 			}
 
-			res, err := chain.QueryPacketCommitment(height, seq)
+			res, err := chain.QueryPacketCommitment(cmd.Context(), height, seq)
 			if err != nil {
 				return err
 			}
@@ -587,7 +587,7 @@ func queryPacketAck() *cobra.Command {
 				return err
 			}
 
-			height, err := chain.QueryLatestHeight()
+			height, err := chain.QueryLatestHeight(cmd.Context())
 			if err != nil {
 				return err
 			}
@@ -597,7 +597,7 @@ func queryPacketAck() *cobra.Command {
 				return err
 			}
 
-			res, err := chain.QueryPacketAck(height, seq)
+			res, err := chain.QueryPacketAck(cmd.Context(), height, seq)
 			if err != nil {
 				return err
 			}
@@ -613,7 +613,7 @@ func queryUnrelayed() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "unrelayed [path]",
 		Aliases: []string{"queue"},
-		Short:   "Query for the packet sequence numbers that remain to be relayed on a given path",
+		Short:   "Query for the packet and acknowledgement sequence numbers that remain to be relayed on a given path",
 		Args:    cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			path, err := config.Paths.Get(args[0])
@@ -634,17 +634,32 @@ func queryUnrelayed() *cobra.Command {
 				return err
 			}
 
-			sh, err := relayer.NewSyncHeaders(c[src], c[dst])
+			sh, err := relayer.NewSyncHeaders(cmd.Context(), c[src], c[dst])
 			if err != nil {
 				return err
 			}
 
-			sp, err := relayer.UnrelayedSequences(c[src], c[dst], sh)
+			strategy, err := path.GetStrategy()
 			if err != nil {
 				return err
 			}
 
-			return c[src].Print(sp, false, false)
+			sp, err := relayer.UnrelayedSequencesForStrategy(cmd.Context(), c[src], c[dst], sh, strategy, path.Ordered())
+			if err != nil {
+				return err
+			}
+
+			ap, err := strategy.UnrelayedAcknowledgements(cmd.Context(), c[src], c[dst], sh)
+			if err != nil {
+				return err
+			}
+
+			out := struct {
+				Packets *relayer.RelaySequences `json:"packets" yaml:"packets"`
+				Acks    *relayer.RelaySequences `json:"acks" yaml:"acks"`
+			}{sp, ap}
+
+			return c[src].Print(out, false, false)
 		},
 	}
 
@@ -669,7 +684,7 @@ func queryFullPathCmd() *cobra.Command {
 				return err
 			}
 
-			stat, err := relayer.QueryPathStatus(c[src], c[dst], path)
+			stat, err := relayer.QueryPathStatus(cmd.Context(), c[src], c[dst], path)
 			if err != nil {
 				return err
 			}
@@ -59,7 +59,7 @@ func updateClientCmd() *cobra.Command {
 				return err
 			}
 
-			dstHeader, err := chains[dst].UpdateLiteWithHeader()
+			dstHeader, err := chains[dst].UpdateLiteWithHeader(cmd.Context())
 			if err != nil {
 				return err
 			}
@@ -83,16 +83,16 @@ func createClientCmd() *cobra.Command {
 				return err
 			}
 
-			dstHeader, err := chains[dst].UpdateLiteWithHeader()
-			if err != nil {
+			if err = chains[src].AddPath(args[2], dcon, dcha, dpor, dord); err != nil {
 				return err
 			}
 
-			if err = chains[src].AddPath(args[2], dcon, dcha, dpor, dord); err != nil {
+			msg, err := chains[src].CreateClientMsg(cmd.Context(), chains[dst])
+			if err != nil {
 				return err
 			}
 
-			return sendAndPrint([]sdk.Msg{chains[src].PathEnd.CreateClient(dstHeader, chains[src].GetTrustingPeriod(), chains[src].MustGetAddress())}, chains[src], cmd)
+			return sendAndPrint([]sdk.Msg{msg}, chains[src], cmd)
 		},
 	}
 	return cmd
@@ -144,27 +144,27 @@ func connTry() *cobra.Command {
 				return err
 			}
 
-			hs, err := relayer.UpdatesWithHeaders(chains[src], chains[dst])
+			hs, err := relayer.UpdatesWithHeaders(cmd.Context(), chains[src], chains[dst])
 			if err != nil {
 				return err
 			}
 
 			// NOTE: We query connection at height - 1 because of the way tendermint returns
 			// proofs the commit for height n is contained in the header of height n + 1
-			dstConnState, err := chains[dst].QueryConnection(hs[dst].Height - 1)
+			dstConnState, err := chains[dst].QueryConnection(cmd.Context(), hs[dst].Height-1)
 			if err != nil {
 				return err
 			}
 
 			// We are querying the state of the client for src on dst and finding the height
-			dstClientState, err := chains[dst].QueryClientState()
+			dstClientState, err := chains[dst].QueryClientState(cmd.Context())
 			if err != nil {
 				return err
 			}
 			dstCsHeight := int64(dstClientState.ClientState.GetLatestHeight())
 
 			// Then we need to query the consensus state for src at that height on dst
-			dstConsState, err := chains[dst].QueryClientConsensusState(hs[dst].Height-1, dstCsHeight)
+			dstConsState, err := chains[dst].QueryClientConsensusState(cmd.Context(), hs[dst].Height-1, dstCsHeight)
 			if err != nil {
 				return err
 			}
@@ -200,27 +200,27 @@ func connAck() *cobra.Command {
 				return err
 			}
 
-			hs, err := relayer.UpdatesWithHeaders(chains[src], chains[dst])
+			hs, err := relayer.UpdatesWithHeaders(cmd.Context(), chains[src], chains[dst])
 			if err != nil {
 				return err
 			}
 
 			// NOTE: We query connection at height - 1 because of the way tendermint returns
 			// proofs the commit for height n is contained in the header of height n + 1
-			dstState, err := chains[dst].QueryConnection(hs[dst].Height - 1)
+			dstState, err := chains[dst].QueryConnection(cmd.Context(), hs[dst].Height-1)
 			if err != nil {
 				return err
 			}
 
 			// We are querying the state of the client for src on dst and finding the height
-			dstClientState, err := chains[dst].QueryClientState()
+			dstClientState, err := chains[dst].QueryClientState(cmd.Context())
 			if err != nil {
 				return err
 			}
 			dstCsHeight := int64(dstClientState.ClientState.GetLatestHeight())
 
 			// Then we need to query the consensus state for src at that height on dst
-			dstConsState, err := chains[dst].QueryClientConsensusState(hs[dst].Height-1, dstCsHeight)
+			dstConsState, err := chains[dst].QueryClientConsensusState(cmd.Context(), hs[dst].Height-1, dstCsHeight)
 			if err != nil {
 				return err
 			}
@@ -256,14 +256,14 @@ func connConfirm() *cobra.Command {
 				return err
 			}
 
-			hs, err := relayer.UpdatesWithHeaders(chains[src], chains[dst])
+			hs, err := relayer.UpdatesWithHeaders(cmd.Context(), chains[src], chains[dst])
 			if err != nil {
 				return err
 			}
 
 			// NOTE: We query connection at height - 1 because of the way tendermint returns
 			// proofs the commit for height n is contained in the header of height n + 1
-			dstState, err := chains[dst].QueryConnection(hs[dst].Height - 1)
+			dstState, err := chains[dst].QueryConnection(cmd.Context(), hs[dst].Height-1)
 			if err != nil {
 				return err
 			}
@@ -301,7 +301,7 @@ func createConnectionStepCmd() *cobra.Command {
 				return err
 			}
 
-			msgs, err := chains[src].CreateConnectionStep(chains[dst])
+			msgs, err := chains[src].CreateConnectionStep(cmd.Context(), chains[dst])
 			if err != nil {
 				return err
 			}
@@ -368,12 +368,12 @@ func chanTry() *cobra.Command {
 				return err
 			}
 
-			dstHeader, err := chains[dst].UpdateLiteWithHeader()
+			dstHeader, err := chains[dst].UpdateLiteWithHeader(cmd.Context())
 			if err != nil {
 				return err
 			}
 
-			dstChanState, err := chains[dst].QueryChannel(dstHeader.Height - 1)
+			dstChanState, err := chains[dst].QueryChannel(cmd.Context(), dstHeader.Height-1)
 			if err != nil {
 				return err
 			}
@@ -409,12 +409,12 @@ func chanAck() *cobra.Command {
 				return err
 			}
 
-			dstHeader, err := chains[dst].UpdateLiteWithHeader()
+			dstHeader, err := chains[dst].UpdateLiteWithHeader(cmd.Context())
 			if err != nil {
 				return err
 			}
 
-			dstChanState, err := chains[dst].QueryChannel(dstHeader.Height - 1)
+			dstChanState, err := chains[dst].QueryChannel(cmd.Context(), dstHeader.Height-1)
 			if err != nil {
 				return err
 			}
@@ -450,12 +450,12 @@ func chanConfirm() *cobra.Command {
 				return err
 			}
 
-			dstHeader, err := chains[dst].UpdateLiteWithHeader()
+			dstHeader, err := chains[dst].UpdateLiteWithHeader(cmd.Context())
 			if err != nil {
 				return err
 			}
 
-			dstChanState, err := chains[dst].QueryChannel(dstHeader.Height - 1)
+			dstChanState, err := chains[dst].QueryChannel(cmd.Context(), dstHeader.Height-1)
 			if err != nil {
 				return err
 			}
@@ -493,7 +493,7 @@ func createChannelStepCmd() *cobra.Command {
 				return err
 			}
 
-			msgs, err := chains[src].CreateChannelStep(chains[dst], ordering)
+			msgs, err := chains[src].CreateChannelStep(cmd.Context(), chains[dst], ordering)
 			if err != nil {
 				return err
 			}
@@ -555,12 +555,12 @@ func chanCloseConfirm() *cobra.Command {
 				return err
 			}
 
-			dstHeader, err := chains[dst].UpdateLiteWithHeader()
+			dstHeader, err := chains[dst].UpdateLiteWithHeader(cmd.Context())
 			if err != nil {
 				return err
 			}
 
-			dstChanState, err := chains[dst].QueryChannel(dstHeader.Height - 1)
+			dstChanState, err := chains[dst].QueryChannel(cmd.Context(), dstHeader.Height-1)
 			if err != nil {
 				return err
 			}
@@ -596,7 +596,7 @@ func closeChannelStepCmd() *cobra.Command {
 				return err
 			}
 
-			msgs, err := chains[src].CloseChannelStep(chains[dst])
+			msgs, err := chains[src].CloseChannelStep(cmd.Context(), chains[dst])
 			if err != nil {
 				return err
 			}
@@ -618,5 +618,5 @@ func closeChannelStepCmd() *cobra.Command {
 }
 
 func sendAndPrint(txs []sdk.Msg, c *relayer.Chain, cmd *cobra.Command) error {
-	return c.SendAndPrint(txs, false, false)
+	return c.SendAndPrint(cmd.Context(), txs, false, false)
 }
@@ -17,9 +17,13 @@ package cmd
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/cosmos/cosmos-sdk/client/flags"
 	"github.com/cosmos/cosmos-sdk/codec"
@@ -56,6 +60,7 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&homePath, flags.FlagHome, defaultHome, "set home directory")
 	rootCmd.PersistentFlags().BoolVarP(&debug, "debug", "d", false, "debug output")
 	rootCmd.PersistentFlags().StringVar(&cfgPath, flagConfig, "config.yaml", "set config file")
+	rootCmd.PersistentFlags().Duration(flagDeadline, 0, "abort the command if it hasn't finished within this duration, 0 for no deadline")
 	if err := viper.BindPFlag(flags.FlagHome, rootCmd.Flags().Lookup(flags.FlagHome)); err != nil {
 		panic(err)
 	}
@@ -65,6 +70,9 @@ func init() {
 	if err := viper.BindPFlag("debug", rootCmd.Flags().Lookup("debug")); err != nil {
 		panic(err)
 	}
+	if err := viper.BindPFlag(flagDeadline, rootCmd.Flags().Lookup(flagDeadline)); err != nil {
+		panic(err)
+	}
 
 	// Register subcommands
 	rootCmd.AddCommand(
@@ -106,17 +114,45 @@ NOTE: Most of the commands have aliases that make typing them much quicker (i.e.
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
-	rootCmd.PersistentPreRunE = func(_ *cobra.Command, _ []string) error {
+	// the context is passed to every command, cancelling it aborts any
+	// queries and txs in flight and shuts down the relayer
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go cancelOnSignal(cancel)
+
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, _ []string) error {
+		deadline, err := cmd.Flags().GetDuration(flagDeadline)
+		if err != nil {
+			return err
+		}
+		if deadline > 0 {
+			time.AfterFunc(deadline, cancel)
+		}
+
 		// reads `homeDir/config/config.yaml` into `var config *Config` before each command
 		return initConfig(rootCmd)
 	}
 
-	if err := rootCmd.Execute(); err != nil {
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
 }
 
+// cancelOnSignal waits for a SIGINT or SIGTERM and then calls cancel, a second
+// signal kills the process if it hasn't shut down by then
+func cancelOnSignal(cancel context.CancelFunc) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+
+	// wait for a signal
+	sig := <-sigCh
+	fmt.Println("Signal Recieved:", sig.String())
+	signal.Stop(sigCh)
+
+	cancel()
+}
+
 // readLineFromBuf reads one line from stdin.
 func readStdin() (string, error) {
 	str, err := bufio.NewReader(os.Stdin).ReadString('\n')
@@ -17,52 +17,87 @@ package cmd
 
 import (
 	"fmt"
-	"os"
-	"os/signal"
-	"syscall"
+	"sort"
 
 	"github.com/cosmos/relayer/relayer"
 	"github.com/spf13/cobra"
 )
 
 // startCmd represents the start command
-// NOTE: This is basically psuedocode
 func startCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:     "start [path-name]",
+		Use:     "start [path-name]...",
 		Aliases: []string{"st"},
-		Short:   "Start the listening relayer on a given path",
-		Args:    cobra.ExactArgs(1),
+		Short:   "Start the listening relayer on the given paths, or all configured paths with --all",
+		Args:    cobra.ArbitraryArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			c, src, dst, err := config.ChainsFromPath(args[0])
+			args, err := pathNamesFromArgs(cmd, args)
 			if err != nil {
 				return err
 			}
 
-			path := config.Paths.MustGet(args[0])
-			done, err := relayer.RunStrategy(c[src], c[dst], path.MustGetStrategy(), path.Ordered())
+			restartDelay, err := getRestartDelay(cmd)
 			if err != nil {
 				return err
 			}
 
-			trapSignal(done)
+			dryRun, err := setDryRun(cmd, config.Chains...)
+			if err != nil {
+				return err
+			}
+
+			sup := relayer.NewPathSupervisor(config.Chains, restartDelay)
+
+			// nothing is submitted in a dry run, so there is no relay state to record
+			if !dryRun {
+				store, err := relayer.OpenRelayStore(homePath)
+				if err != nil {
+					return err
+				}
+				defer store.Close()
+				sup.UseStore(store)
+			}
+			for _, name := range args {
+				path, err := config.Paths.Get(name)
+				if err != nil {
+					return err
+				}
+				if err = sup.Add(name, path); err != nil {
+					return err
+				}
+			}
+
+			if err = sup.Start(cmd.Context()); err != nil {
+				return err
+			}
+
+			// relay until the command is cancelled by a signal or its deadline
+			<-cmd.Context().Done()
+			sup.Stop()
 			return nil
 		},
 	}
-	return cmd
+	return dryRunFlag(allFlag(restartDelayFlag(cmd)))
 }
 
-// trap signal waits for a SIGINT or SIGTERM and then sends down the done channel
-func trapSignal(done func()) {
-	sigCh := make(chan os.Signal, 1)
-
-	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
-
-	// wait for a signal
-	sig := <-sigCh
-	fmt.Println("Signal Recieved:", sig.String())
-	close(sigCh)
+// pathNamesFromArgs returns the path names passed as args, or the names
+// of all configured paths if the --all flag is set
+func pathNamesFromArgs(cmd *cobra.Command, args []string) ([]string, error) {
+	all, err := cmd.Flags().GetBool(flagAll)
+	if err != nil {
+		return nil, err
+	}
 
-	// call the cleanup func
-	done()
+	switch {
+	case all && len(args) > 0:
+		return nil, fmt.Errorf("can't pass path names with --all")
+	case all:
+		for name := range config.Paths {
+			args = append(args, name)
+		}
+		sort.Strings(args)
+	case len(args) == 0:
+		return nil, fmt.Errorf("must pass at least one path name or --all")
+	}
+	return args, nil
 }
@@ -47,7 +47,7 @@ func faucetRequestCmd() *cobra.Command {
 			}
 
 			if urlString == "" {
-				u, err := url.Parse(chain.RPCAddr)
+				u, err := url.Parse(chain.GetRPCAddrs()[0])
 				if err != nil {
 					return err
 				}
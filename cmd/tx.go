@@ -37,10 +37,12 @@ func transactionCmd() *cobra.Command {
 
 	cmd.AddCommand(
 		fullPathCmd(),
+		recoverCmd(),
 		relayMsgsCmd(),
 		transferCmd(),
 		flags.LineBreak,
 		createClientsCmd(),
+		updateClientsCmd(),
 		createConnectionCmd(),
 		createChannelCmd(),
 		closeChannelCmd(),
@@ -64,12 +66,47 @@ func createClientsCmd() *cobra.Command {
 				return err
 			}
 
-			return c[src].CreateClients(c[dst])
+			return c[src].CreateClients(cmd.Context(), c[dst])
 		},
 	}
 	return cmd
 }
 
+func updateClientsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "update-clients [path-name]...",
+		Aliases: []string{"uc"},
+		Short:   "update the clients on the given paths, or all configured paths with --all",
+		Long:    "This command updates the clients on both chains of each path with the latest header of their counterparty, it is meant to be run periodically to keep the clients on quiet paths from expiring",
+		Args:    cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			args, err := pathNamesFromArgs(cmd, args)
+			if err != nil {
+				return err
+			}
+
+			var failed []string
+			for _, name := range args {
+				c, src, dst, err := config.ChainsFromPath(name)
+				if err != nil {
+					return err
+				}
+
+				if err = c[src].UpdateClients(cmd.Context(), c[dst]); err != nil {
+					c[src].Error(fmt.Errorf("path %s: %w", name, err))
+					failed = append(failed, name)
+				}
+			}
+
+			if len(failed) > 0 {
+				return fmt.Errorf("failed to update clients on paths %v", failed)
+			}
+			return nil
+		},
+	}
+	return allFlag(cmd)
+}
+
 func createConnectionCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "connection [path-name]",
@@ -83,16 +120,20 @@ func createConnectionCmd() *cobra.Command {
 				return err
 			}
 
+			if _, err = setDryRun(cmd, c[src], c[dst]); err != nil {
+				return err
+			}
+
 			to, err := getTimeout(cmd)
 			if err != nil {
 				return err
 			}
 
-			return c[src].CreateConnection(c[dst], to)
+			return c[src].CreateConnection(cmd.Context(), c[dst], to)
 		},
 	}
 
-	return timeoutFlag(cmd)
+	return dryRunFlag(timeoutFlag(cmd))
 }
 
 func createChannelCmd() *cobra.Command {
@@ -108,17 +149,20 @@ func createChannelCmd() *cobra.Command {
 				return err
 			}
 
+			if _, err = setDryRun(cmd, c[src], c[dst]); err != nil {
+				return err
+			}
+
 			to, err := getTimeout(cmd)
 			if err != nil {
 				return err
 			}
 
-			// TODO: read order out of path config
-			return c[src].CreateChannel(c[dst], true, to)
+			return c[src].CreateChannel(cmd.Context(), c[dst], config.Paths.MustGet(args[0]).Ordered(), to)
 		},
 	}
 
-	return timeoutFlag(cmd)
+	return dryRunFlag(timeoutFlag(cmd))
 }
 
 func closeChannelCmd() *cobra.Command {
@@ -139,7 +183,7 @@ func closeChannelCmd() *cobra.Command {
 				return err
 			}
 
-			return c[src].CloseChannel(c[dst], to)
+			return c[src].CloseChannel(cmd.Context(), c[dst], to)
 		},
 	}
 
@@ -158,31 +202,125 @@ func fullPathCmd() *cobra.Command {
 				return err
 			}
 
-			to, err := getTimeout(cmd)
+			dryRun, err := setDryRun(cmd, c[src], c[dst])
+			if err != nil {
+				return err
+			}
+
+			linker, err := getLinker(cmd)
+			if err != nil {
+				return err
+			}
+
+			// the progress is saved in the path after each step, so that
+			// rerunning link after a failure resumes where it stopped
+			pth := config.Paths.MustGet(args[0])
+			if pth.Link == nil {
+				pth.Link = &relayer.LinkProgress{}
+			}
+			linker.Progress = pth.Link
+			linker.OnProgress = func() error { return overWriteConfig(cmd, config) }
+
+			err = linker.Link(cmd.Context(), c[src], c[dst], pth.Ordered())
+			if !dryRun {
+				fmt.Printf("Link summary for %s:\n%s", args[0], pth.Link.Summary())
+			}
+			return err
+		},
+	}
+
+	return linkRetryFlags(dryRunFlag(timeoutFlag(cmd)))
+}
+
+func recoverCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "recover [path-name]",
+		Short: "replace the expired or frozen clients on a path and link it again with new identifiers",
+		Long: strings.TrimSpace(`Checks the clients on both ends of the path. If either of them has expired or been frozen,
+a new client is created in its place along with a new connection and channel on both ends, and the path's
+identifiers are updated in the config. The packets left on the old channel are reported, along with
+whether they can still be relayed over it. If recover is interrupted, finish linking the path with 'rly tx link'.`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, src, dst, err := config.ChainsFromPath(args[0])
 			if err != nil {
 				return err
 			}
 
-			if err = c[src].CreateClients(c[dst]); err != nil {
+			dryRun, err := setDryRun(cmd, c[src], c[dst])
+			if err != nil {
 				return err
 			}
 
-			if err = c[src].CreateConnection(c[dst], to); err != nil {
+			linker, err := getLinker(cmd)
+			if err != nil {
 				return err
 			}
 
-			return c[src].CreateChannel(c[dst], true, to)
+			pth := config.Paths.MustGet(args[0])
+			recovery, err := relayer.RecoverPath(cmd.Context(), c[src], c[dst], pth)
+			if err != nil {
+				return err
+			}
+			if recovery == nil {
+				fmt.Printf("the clients on path %s can still be used, there is nothing to recover\n", args[0])
+				return nil
+			}
+			// the old path is kept under a new name if anything left on it can still be relayed
+			if recovery.Relayable() {
+				recovery.OldName = oldPathName(args[0])
+			}
+			fmt.Print(recovery.Report())
+
+			if err = c[src].SetPath(recovery.New.Src); err != nil {
+				return err
+			}
+			if err = c[dst].SetPath(recovery.New.Dst); err != nil {
+				return err
+			}
+
+			// the new identifiers are saved before linking, so that an
+			// interrupted recovery can be finished with rly tx link
+			if !dryRun {
+				recovery.New.Link = &relayer.LinkProgress{}
+				config.Paths[args[0]] = recovery.New
+				if recovery.OldName != "" {
+					config.Paths[recovery.OldName] = recovery.Old
+				}
+				if err = overWriteConfig(cmd, config); err != nil {
+					return err
+				}
+				linker.Progress = recovery.New.Link
+				linker.OnProgress = func() error { return overWriteConfig(cmd, config) }
+			}
+
+			err = linker.Link(cmd.Context(), c[src], c[dst], recovery.New.Ordered())
+			if !dryRun {
+				fmt.Printf("Link summary for %s:\n%s", args[0], recovery.New.Link.Summary())
+			}
+			return err
 		},
 	}
 
-	return timeoutFlag(cmd)
+	return linkRetryFlags(dryRunFlag(timeoutFlag(cmd)))
+}
+
+// oldPathName returns an unused name to keep the path with the given name under once it is recovered
+func oldPathName(name string) string {
+	old := name + "-old"
+	for i := 2; ; i++ {
+		if _, found := config.Paths[old]; !found {
+			return old
+		}
+		old = fmt.Sprintf("%s-old-%d", name, i)
+	}
 }
 
 func relayMsgsCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "relay [path-name]",
 		Aliases: []string{"rly", "queue"},
-		Short:   "relay any packets that remain to be relayed on a given path, in both directions",
+		Short:   "relay any packets and acknowledgements that remain to be relayed on a given path, in both directions",
 		Args:    cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			c, src, dst, err := config.ChainsFromPath(args[0])
@@ -190,25 +328,30 @@ func relayMsgsCmd() *cobra.Command {
 				return err
 			}
 
-			sh, err := relayer.NewSyncHeaders(c[src], c[dst])
+			if _, err = setDryRun(cmd, c[src], c[dst]); err != nil {
+				return err
+			}
+
+			sh, err := relayer.NewSyncHeaders(cmd.Context(), c[src], c[dst])
 			if err != nil {
 				return err
 			}
 
-			sp, err := relayer.UnrelayedSequences(c[src], c[dst], sh)
+			path := config.Paths.MustGet(args[0])
+			strategy, err := path.GetStrategy()
 			if err != nil {
 				return err
 			}
 
-			if err = relayer.RelayPacketsOrderedChan(c[src], c[dst], sh, sp); err != nil {
+			if err = relayer.RelayUnrelayedPackets(cmd.Context(), c[src], c[dst], sh, strategy, path.Ordered()); err != nil {
 				return err
 			}
 
-			return nil
+			return relayer.RelayUnrelayedAcks(cmd.Context(), c[src], c[dst], sh, strategy)
 		},
 	}
 
-	return cmd
+	return dryRunFlag(cmd)
 }
 
 func sendPacketCmd() *cobra.Command {
@@ -244,7 +387,7 @@ func sendPacketCmd() *cobra.Command {
 				packetData = args[2]
 			}
 
-			return c[src].SendPacket(c[dst], []byte(packetData))
+			return c[src].SendPacket(cmd.Context(), c[dst], []byte(packetData))
 		},
 	}
 	return pathFlag(cmd)
@@ -48,7 +48,7 @@ func xfersend() *cobra.Command {
 				return err
 			}
 
-			return c[src].SendTransferMsg(c[dst], amount, dstAddr, source)
+			return c[src].SendTransferMsg(cmd.Context(), c[dst], amount, dstAddr, source)
 		},
 	}
 	return pathFlag(cmd)
@@ -92,7 +92,7 @@ func transferCmd() *cobra.Command {
 				return err
 			}
 
-			return c[src].SendTransferBothSides(c[dst], amount, dstAddr, source)
+			return c[src].SendTransferBothSides(cmd.Context(), c[dst], amount, dstAddr, source)
 		},
 	}
 	return pathFlag(cmd)
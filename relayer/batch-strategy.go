@@ -0,0 +1,369 @@
+package relayer
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	chanState "github.com/cosmos/cosmos-sdk/x/ibc/04-channel/exported"
+)
+
+const (
+	batchParamMaxMsgs    = "max-msgs"
+	batchParamMaxTxBytes = "max-tx-bytes"
+	batchParamMaxWait    = "max-wait"
+)
+
+var (
+	defaultBatchMaxMsgs    = 20
+	defaultBatchMaxTxBytes = 200000
+	defaultBatchMaxWait    = time.Second * 5
+)
+
+func init() {
+	RegisterStrategy((&BatchStrategy{}).GetType(), func(path *Path) (Strategy, error) {
+		bs, err := newBatchStrategy(path.Strategy)
+		if err != nil {
+			return nil, err
+		}
+		bs.Filter = path.Filter
+		return bs, nil
+	})
+}
+
+// NewBatchStrategy returns the proper config for the BatchStrategy
+func NewBatchStrategy() *StrategyCfg {
+	return &StrategyCfg{
+		Type: (&BatchStrategy{}).GetType(),
+		Params: map[string]string{
+			batchParamMaxMsgs:    strconv.Itoa(defaultBatchMaxMsgs),
+			batchParamMaxTxBytes: strconv.Itoa(defaultBatchMaxTxBytes),
+			batchParamMaxWait:    defaultBatchMaxWait.String(),
+		},
+	}
+}
+
+// BatchStrategy is an implementation of Strategy that collects packets from events
+// and relays them together, flushing a batch when it reaches MaxMsgs or MaxTxBytes
+// or when MaxWait has passed since the first packet was added. Backlogs are split
+// into transactions that respect the same limits.
+type BatchStrategy struct {
+	MaxMsgs    int
+	MaxTxBytes int
+	MaxWait    time.Duration
+	Filter     *PacketFilter
+
+	mu      sync.Mutex
+	batches map[string]*packetBatch
+	retries *retryQueue
+}
+
+// packetBatch holds the packets waiting to be relayed to a single chain
+type packetBatch struct {
+	ctx      context.Context
+	src, dst *Chain
+	sh       *SyncHeaders
+	packets  []relayPacket
+	bytes    int
+	timer    *time.Timer
+
+	// flushed batches waiting to be sent, these are sent one at a time
+	// and in order so packets on ordered channels stay in sequence
+	pending [][]relayPacket
+	sending bool
+}
+
+func newBatchStrategy(cfg *StrategyCfg) (bs *BatchStrategy, err error) {
+	if err = cfg.CheckParams(append([]string{batchParamMaxMsgs, batchParamMaxTxBytes, batchParamMaxWait}, retryParams...)...); err != nil {
+		return nil, err
+	}
+
+	bs = &BatchStrategy{batches: make(map[string]*packetBatch)}
+	if bs.retries, err = newRetryQueue(cfg); err != nil {
+		return nil, err
+	}
+	if bs.MaxMsgs, err = cfg.IntParam(batchParamMaxMsgs, defaultBatchMaxMsgs); err != nil {
+		return nil, err
+	}
+	if bs.MaxTxBytes, err = cfg.IntParam(batchParamMaxTxBytes, defaultBatchMaxTxBytes); err != nil {
+		return nil, err
+	}
+	if bs.MaxWait, err = cfg.DurationParam(batchParamMaxWait, defaultBatchMaxWait); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case bs.MaxMsgs < 2:
+		// one message in every transaction is taken by the update client
+		return nil, fmt.Errorf("%s must be at least 2, got %d", batchParamMaxMsgs, bs.MaxMsgs)
+	case bs.MaxTxBytes < 0:
+		return nil, fmt.Errorf("%s can't be negative, got %d", batchParamMaxTxBytes, bs.MaxTxBytes)
+	case bs.MaxWait <= 0:
+		return nil, fmt.Errorf("%s must be positive, got %s", batchParamMaxWait, bs.MaxWait)
+	}
+
+	return bs, nil
+}
+
+// GetType implements Strategy
+func (bs *BatchStrategy) GetType() string {
+	return "batch"
+}
+
+// UnrelayedSequencesOrdered returns the unrelayed sequence numbers between two chains
+func (bs *BatchStrategy) UnrelayedSequencesOrdered(ctx context.Context, src, dst *Chain, sh *SyncHeaders) (*RelaySequences, error) {
+	return UnrelayedSequences(ctx, src, dst, sh)
+}
+
+// UnrelayedSequencesUnordered returns the unrelayed sequence numbers between two chains
+func (bs *BatchStrategy) UnrelayedSequencesUnordered(ctx context.Context, src, dst *Chain, sh *SyncHeaders) (*RelaySequences, error) {
+	return UnrelayedSequencesUnordered(ctx, src, dst, sh)
+}
+
+// UnrelayedAcknowledgements returns the sequence numbers of the acknowledgements
+// that have not been relayed back to the sending chain
+func (bs *BatchStrategy) UnrelayedAcknowledgements(ctx context.Context, src, dst *Chain, sh *SyncHeaders) (*RelaySequences, error) {
+	return UnrelayedAcknowledgements(ctx, src, dst, sh)
+}
+
+// HandleEvents adds the packets in the events to the batch for the chain they are relayed to
+func (bs *BatchStrategy) HandleEvents(ctx context.Context, src, dst *Chain, sh *SyncHeaders, events map[string][]string) {
+	rlyPackets, err := relayPacketsFromEventListener(src.PathEnd, dst.PathEnd, events, bs.Filter)
+	if len(rlyPackets) == 0 || err != nil {
+		return
+	}
+
+	rlyPackets, timeouts := splitTimedOutPackets(rlyPackets, sh.GetHeader(src.ChainID), src.PathEnd.getOrder() == chanState.ORDERED)
+	rlyPackets, timeouts = src.store.observe(src, rlyPackets), dst.store.observe(dst, timeouts)
+	for _, rp := range rlyPackets {
+		bs.add(ctx, src, dst, sh, rp)
+	}
+
+	// packets that have timed out on src are timed out on dst, where they were sent
+	for _, rp := range timeouts {
+		bs.add(ctx, dst, src, sh, rp)
+	}
+}
+
+// add queues a packet to be relayed to src, flushing the batch when
+// the packet fills it or would take it over its limits
+func (bs *BatchStrategy) add(ctx context.Context, src, dst *Chain, sh *SyncHeaders, rp relayPacket) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	b, ok := bs.batches[src.ChainID]
+	if !ok {
+		b = &packetBatch{src: src, dst: dst}
+		bs.batches[src.ChainID] = b
+	}
+
+	// NOTE: the size of the proofs isn't known until the batch is flushed, so only
+	// the packet data is counted here and the flush splits the batch if needed
+	size := len(rp.Data())
+	if len(b.packets) > 0 && bs.MaxTxBytes > 0 && b.bytes+size > bs.MaxTxBytes {
+		bs.flushLocked(b)
+	}
+
+	b.ctx, b.sh = ctx, sh
+	b.packets = append(b.packets, rp)
+	b.bytes += size
+
+	switch {
+	// one message in every transaction is taken by the update client
+	case len(b.packets)+1 >= bs.MaxMsgs:
+		bs.flushLocked(b)
+	case len(b.packets) == 1:
+		var timer *time.Timer
+		timer = time.AfterFunc(bs.MaxWait, func() {
+			bs.mu.Lock()
+			defer bs.mu.Unlock()
+			// the batch the timer was started for may have been flushed while this was
+			// waiting for the lock, in which case b holds a newer batch with its own timer
+			if b.timer == timer {
+				bs.flushLocked(b)
+			}
+		})
+		b.timer = timer
+	}
+}
+
+// flushLocked moves the packets in the batch to the pending queue and starts
+// sending the queue if it isn't already being sent, the caller must hold bs.mu
+func (bs *BatchStrategy) flushLocked(b *packetBatch) {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	if len(b.packets) == 0 {
+		return
+	}
+
+	b.pending = append(b.pending, b.packets)
+	b.packets, b.bytes = nil, 0
+	if b.sending {
+		return
+	}
+
+	b.sending = true
+	go func() {
+		for {
+			bs.mu.Lock()
+			if len(b.pending) == 0 {
+				b.sending = false
+				bs.mu.Unlock()
+				return
+			}
+			ctx, packets, sh := b.ctx, b.pending[0], b.sh
+			b.pending = b.pending[1:]
+			bs.mu.Unlock()
+
+			bs.sendPackets(ctx, b.src, b.dst, packets, sh)
+		}
+	}()
+}
+
+// sendPackets fetches the proofs for the packets at the latest synced height and sends
+// them to src, split into transactions that respect the batch limits. The packets in
+// any transaction that fails are added to the retry queue.
+func (bs *BatchStrategy) sendPackets(ctx context.Context, src, dst *Chain, packets []relayPacket, sh *SyncHeaders) {
+	msgs, sent := []sdk.Msg{}, []relayPacket{}
+	for _, rp := range packets {
+		if err := rp.FetchCommitResponse(ctx, src, dst, sh); err != nil {
+			// we don't expect many errors here because of the retry
+			// in FetchCommitResponse
+			src.Error(err)
+			continue
+		}
+		msgs = append(msgs, rp.Msg(src, dst))
+		sent = append(sent, rp)
+	}
+
+	failed := []relayPacket{}
+	for _, i := range bs.sendBatches(ctx, src, dst, msgs, sh) {
+		failed = append(failed, sent[i])
+	}
+	if len(failed) > 0 {
+		bs.retries.add(ctx, src, dst, failed, sh)
+	}
+}
+
+// RelayPacketsUnorderedChan creates transactions to relay un-relayed messages, any packet
+// that can't be fetched is skipped and left to be picked up again later
+// CONTRACT: the SyncHeaders passed in here must be up to date or being kept updated
+func (bs *BatchStrategy) RelayPacketsUnorderedChan(ctx context.Context, src, dst *Chain, sp *RelaySequences, sh *SyncHeaders) error {
+	return bs.relayPackets(ctx, src, dst, sp, sh, false)
+}
+
+// RelayPacketsOrderedChan creates transactions to clear both queues
+// CONTRACT: the SyncHeaders passed in here must be up to date or being kept updated
+func (bs *BatchStrategy) RelayPacketsOrderedChan(ctx context.Context, src, dst *Chain, sp *RelaySequences, sh *SyncHeaders) error {
+	return bs.relayPackets(ctx, src, dst, sp, sh, true)
+}
+
+func (bs *BatchStrategy) relayPackets(ctx context.Context, src, dst *Chain, sp *RelaySequences, sh *SyncHeaders, ordered bool) error {
+	msgs, err := packetRelayMsgs(ctx, src, dst, sp, sh, ordered, bs.Filter)
+	if err != nil {
+		return err
+	}
+
+	if !msgs.Ready() {
+		src.Log(fmt.Sprintf("- No packets to relay between [%s]port{%s} and [%s]port{%s}", src.ChainID, src.PathEnd.PortID, dst.ChainID, dst.PathEnd.PortID))
+		return nil
+	}
+
+	bs.sendMsgs(ctx, src, dst, msgs.Src, sh)
+	bs.sendMsgs(ctx, dst, src, msgs.Dst, sh)
+	return nil
+}
+
+// RelayAcknowledgements creates transactions to relay the acknowledgements written on each
+// chain back to the chain that sent the packet. Any ack that can't be fetched is skipped and
+// left to be picked up again later
+// CONTRACT: the SyncHeaders passed in here must be up to date or being kept updated
+func (bs *BatchStrategy) RelayAcknowledgements(ctx context.Context, src, dst *Chain, sp *RelaySequences, sh *SyncHeaders) error {
+	msgs := ackRelayMsgs(ctx, src, dst, sp, sh, bs.Filter)
+	if !msgs.Ready() {
+		src.Log(fmt.Sprintf("- No acknowledgements to relay between [%s]port{%s} and [%s]port{%s}", src.ChainID, src.PathEnd.PortID, dst.ChainID, dst.PathEnd.PortID))
+		return nil
+	}
+
+	bs.sendMsgs(ctx, src, dst, msgs.Src, sh)
+	bs.sendMsgs(ctx, dst, src, msgs.Dst, sh)
+	return nil
+}
+
+// sendMsgs sends the packet msgs to src in batches, the packets in any
+// transaction that fails are added to the retry queue
+func (bs *BatchStrategy) sendMsgs(ctx context.Context, src, dst *Chain, msgs []sdk.Msg, sh *SyncHeaders) {
+	failed := []relayPacket{}
+	for _, i := range bs.sendBatches(ctx, src, dst, msgs, sh) {
+		if rp, ok := relayPacketFromMsg(msgs[i]); ok {
+			failed = append(failed, rp)
+		}
+	}
+	if len(failed) > 0 {
+		bs.retries.add(ctx, src, dst, failed, sh)
+	}
+}
+
+// sendBatches sends msgs to src in transactions of at most MaxMsgs messages and MaxTxBytes,
+// the update client message is only added until a transaction containing it succeeds. It
+// returns the indexes of the msgs in transactions that failed or left out packets that are
+// already being relayed by another transaction. On ORDERED channels the msgs after such a
+// transaction can't succeed either, so they aren't sent and are returned too.
+func (bs *BatchStrategy) sendBatches(ctx context.Context, src, dst *Chain, msgs []sdk.Msg, sh *SyncHeaders) (failed []int) {
+	updated, ordered := false, src.PathEnd.getOrder() == chanState.ORDERED
+	for offset := 0; len(msgs) > 0; {
+		txs := &RelayMsgs{Src: []sdk.Msg{}, Dst: []sdk.Msg{}}
+		if !updated {
+			txs.Src = append(txs.Src, src.PathEnd.UpdateClient(sh.GetHeader(dst.ChainID), src.MustGetAddress()))
+		}
+
+		var size, n int
+		for _, msg := range msgs {
+			msgSize := bs.msgSize(src, msg)
+			if n > 0 && (len(txs.Src) >= bs.MaxMsgs || (bs.MaxTxBytes > 0 && size+msgSize > bs.MaxTxBytes)) {
+				break
+			}
+			txs.Src = append(txs.Src, msg)
+			size += msgSize
+			n++
+		}
+		msgs = msgs[n:]
+
+		withUpdate := !updated
+		if txs.Send(ctx, src, dst); !txs.success {
+			for i := offset; i < offset+n; i++ {
+				failed = append(failed, i)
+			}
+			if ordered {
+				for i := offset + n; i < offset+n+len(msgs); i++ {
+					failed = append(failed, i)
+				}
+				return failed
+			}
+		} else if len(txs.Src) > 0 {
+			// packets that are already being relayed are left out when the tx is sent
+			relayed := len(txs.Src)
+			if withUpdate {
+				relayed--
+			}
+			updated = true
+			src.logPacketsRelayed(dst, relayed)
+		}
+		offset += n
+	}
+	return failed
+}
+
+// msgSize returns the encoded size of the msg, which is used to estimate the size of the tx
+func (bs *BatchStrategy) msgSize(c *Chain, msg sdk.Msg) int {
+	bz, err := c.Amino.MarshalBinaryBare(msg)
+	if err != nil {
+		return 0
+	}
+	return len(bz)
+}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	sdkCtx "github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/client/flags"
 	ckeys "github.com/cosmos/cosmos-sdk/client/keys"
 	aminocodec "github.com/cosmos/cosmos-sdk/codec"
 	codecstd "github.com/cosmos/cosmos-sdk/codec/std"
@@ -19,8 +20,10 @@ import (
 	keys "github.com/cosmos/cosmos-sdk/crypto/keyring"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/x/auth"
+	authclient "github.com/cosmos/cosmos-sdk/x/auth/client"
 	"github.com/cosmos/go-bip39"
 	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/libs/service"
 	rpcclient "github.com/tendermint/tendermint/rpc/client"
 	rpchttp "github.com/tendermint/tendermint/rpc/client/http"
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
@@ -29,16 +32,21 @@ import (
 
 // Chain represents the necessary data for connecting to and indentifying a chain and its counterparites
 type Chain struct {
-	Key            string  `yaml:"key" json:"key"`
-	ChainID        string  `yaml:"chain-id" json:"chain-id"`
-	RPCAddr        string  `yaml:"rpc-addr" json:"rpc-addr"`
-	AccountPrefix  string  `yaml:"account-prefix" json:"account-prefix"`
-	Gas            uint64  `yaml:"gas,omitempty" json:"gas,omitempty"`
-	GasAdjustment  float64 `yaml:"gas-adjustment,omitempty" json:"gas-adjustment,omitempty"`
-	GasPrices      string  `yaml:"gas-prices,omitempty" json:"gas-prices,omitempty"`
-	DefaultDenom   string  `yaml:"default-denom,omitempty" json:"default-denom,omitempty"`
-	Memo           string  `yaml:"memo,omitempty" json:"memo,omitempty"`
-	TrustingPeriod string  `yaml:"trusting-period" json:"trusting-period"`
+	Key            string   `yaml:"key" json:"key"`
+	ChainID        string   `yaml:"chain-id" json:"chain-id"`
+	RPCAddr        string   `yaml:"rpc-addr" json:"rpc-addr"`
+	RPCAddrs       []string `yaml:"rpc-addrs,omitempty" json:"rpc-addrs,omitempty"`
+	AccountPrefix  string   `yaml:"account-prefix" json:"account-prefix"`
+	Gas            uint64   `yaml:"gas,omitempty" json:"gas,omitempty"`
+	GasAdjustment  float64  `yaml:"gas-adjustment,omitempty" json:"gas-adjustment,omitempty"`
+	GasPrices      string   `yaml:"gas-prices,omitempty" json:"gas-prices,omitempty"`
+	SimulateGas    bool     `yaml:"simulate-gas,omitempty" json:"simulate-gas,omitempty"`
+	MaxGas         uint64   `yaml:"max-gas,omitempty" json:"max-gas,omitempty"`
+	BroadcastMode  string   `yaml:"broadcast-mode,omitempty" json:"broadcast-mode,omitempty"`
+	Witnesses      []string `yaml:"witnesses,omitempty" json:"witnesses,omitempty"`
+	DefaultDenom   string   `yaml:"default-denom,omitempty" json:"default-denom,omitempty"`
+	Memo           string   `yaml:"memo,omitempty" json:"memo,omitempty"`
+	TrustingPeriod string   `yaml:"trusting-period" json:"trusting-period"`
 
 	// TODO: make these private
 	HomePath string                `yaml:"-" json:"-"`
@@ -50,47 +58,63 @@ type Chain struct {
 
 	address sdk.AccAddress
 	logger  log.Logger
+	rpc     *failoverClient
 	timeout time.Duration
 	debug   bool
 
 	// stores facuet addresses that have been used reciently
 	faucetAddrs map[string]time.Time
+
+	// signs txs for Key, shared by every path using the chain
+	signer *txSigner
+
+	// records the relay state of packets, only set while relaying with rly start
+	store *RelayStore
+
+	// logs the txs that would be sent instead of broadcasting them
+	dryRun bool
+
+	// max clock drift allowed by the clients created on the chain
+	maxClockDrift time.Duration
+
+	// providers for the Witnesses, built once in Init
+	witnesses []witness
 }
 
-// ListenRPCEmitJSON listens for tx and block events from a chain and outputs them as JSON to stdout
-func (src *Chain) ListenRPCEmitJSON(tx, block, data bool) func() {
-	doneChan := make(chan struct{})
-	go src.listenLoop(doneChan, tx, block, data)
-	return func() { doneChan <- struct{}{} }
+// ListenRPCEmitJSON listens for tx and block events from a chain and outputs them
+// as JSON to stdout, it blocks until ctx is done
+func (src *Chain) ListenRPCEmitJSON(ctx context.Context, tx, block, data bool) {
+	src.listenLoop(ctx, tx, block, data)
 }
 
-func (src *Chain) listenLoop(doneChan chan struct{}, tx, block, data bool) {
+func (src *Chain) listenLoop(ctx context.Context, tx, block, data bool) {
 	// Subscribe to source chain
-	if err := src.Start(); err != nil {
-		src.Error(err)
-		return
-	}
-
-	srcTxEvents, srcTxCancel, err := src.Subscribe(txEvents)
+	sub, err := src.subscribeEvents(ctx)
 	if err != nil {
 		src.Error(err)
 		return
 	}
-	defer srcTxCancel()
+	defer func() {
+		if sub != nil {
+			sub.close()
+		}
+	}()
 
-	srcBlockEvents, srcBlockCancel, err := src.Subscribe(blEvents)
-	if err != nil {
-		src.Error(err)
-		return
-	}
-	defer srcBlockCancel()
+	stallTicker := time.NewTicker(listenStallTimeout / 4)
+	defer stallTicker.Stop()
 
 	// Listen to channels and take appropriate action
 	var byt []byte
 	var mar interface{}
 	for {
 		select {
-		case srcMsg := <-srcTxEvents:
+		case srcMsg, ok := <-sub.txs:
+			if !ok {
+				if sub, ok = sub.resubscribe(ctx); !ok {
+					return
+				}
+				continue
+			}
 			if tx {
 				continue
 			} else if data {
@@ -102,7 +126,14 @@ func (src *Chain) listenLoop(doneChan chan struct{}, tx, block, data bool) {
 				src.Error(err)
 			}
 			fmt.Println(string(byt))
-		case srcMsg := <-srcBlockEvents:
+		case srcMsg, ok := <-sub.blocks:
+			if !ok {
+				if sub, ok = sub.resubscribe(ctx); !ok {
+					return
+				}
+				continue
+			}
+			sub.lastBlock = time.Now()
 			if block {
 				continue
 			} else if data {
@@ -114,8 +145,16 @@ func (src *Chain) listenLoop(doneChan chan struct{}, tx, block, data bool) {
 				src.Error(err)
 			}
 			fmt.Println(string(byt))
-		case <-doneChan:
-			close(doneChan)
+		case <-stallTicker.C:
+			if !sub.stalled() {
+				continue
+			}
+			src.Log(fmt.Sprintf("- no blocks from %s in %s, reconnecting...", src.ChainID, listenStallTimeout))
+			var ok bool
+			if sub, ok = sub.resubscribe(ctx); !ok {
+				return
+			}
+		case <-ctx.Done():
 			return
 		}
 	}
@@ -129,7 +168,8 @@ func (src *Chain) Init(homePath string, cdc *codecstd.Codec, amino *aminocodec.C
 		return err
 	}
 
-	client, err := newRPCClient(src.RPCAddr, timeout)
+	logger := defaultChainLogger()
+	client, err := newFailoverClient(src.ChainID, src.GetRPCAddrs(), timeout, logger)
 	if err != nil {
 		return err
 	}
@@ -144,16 +184,28 @@ func (src *Chain) Init(homePath string, cdc *codecstd.Codec, amino *aminocodec.C
 		return fmt.Errorf("failed to parse trusting period (%s) for chain %s", src.TrustingPeriod, src.ChainID)
 	}
 
+	if err = validateBroadcastMode(src.BroadcastMode); err != nil {
+		return fmt.Errorf("invalid broadcast mode for chain %s: %w", src.ChainID, err)
+	}
+
+	witnesses, err := newWitnesses(src.ChainID, src.Witnesses)
+	if err != nil {
+		return fmt.Errorf("invalid witnesses for chain %s: %w", src.ChainID, err)
+	}
+
 	src.Keybase = keybase
 	src.Client = client
+	src.rpc = client
 	src.Cdc = newContextualStdCodec(cdc, src.UseSDKContext)
 	src.Amino = newContextualAminoCodec(amino, src.UseSDKContext)
 	RegisterCodec(amino)
 	src.HomePath = homePath
-	src.logger = defaultChainLogger()
+	src.logger = logger
 	src.timeout = timeout
 	src.debug = debug
 	src.faucetAddrs = make(map[string]time.Time)
+	src.signer = &txSigner{}
+	src.witnesses = witnesses
 	return nil
 }
 
@@ -181,6 +233,19 @@ func (src *Chain) getGasPrices() sdk.DecCoins {
 	return gp
 }
 
+// GetRPCAddrs returns the chain's rpc addresses in order of preference, rpc-addrs
+// takes precedence over rpc-addr if both are set
+func (src *Chain) GetRPCAddrs() []string {
+	switch {
+	case len(src.RPCAddrs) > 0:
+		return src.RPCAddrs
+	case src.RPCAddr != "":
+		return []string{src.RPCAddr}
+	default:
+		return nil
+	}
+}
+
 // GetTrustingPeriod returns the trusting period for the chain
 func (src *Chain) GetTrustingPeriod() time.Duration {
 	tp, _ := time.ParseDuration(src.TrustingPeriod)
@@ -204,33 +269,145 @@ func newRPCClient(addr string, timeout time.Duration) (*rpchttp.HTTP, error) {
 }
 
 // SendMsg wraps the msg in a stdtx, signs and sends it
-func (src *Chain) SendMsg(datagram sdk.Msg) (sdk.TxResponse, error) {
-	return src.SendMsgs([]sdk.Msg{datagram})
+func (src *Chain) SendMsg(ctx context.Context, datagram sdk.Msg) (sdk.TxResponse, error) {
+	return src.SendMsgs(ctx, []sdk.Msg{datagram})
 }
 
-// SendMsgs wraps the msgs in a stdtx, signs and sends it
-func (src *Chain) SendMsgs(datagrams []sdk.Msg) (res sdk.TxResponse, err error) {
-	var out []byte
-	if out, err = src.BuildAndSignTx(datagrams); err != nil {
+// SendMsgs wraps the msgs in a stdtx, signs and sends it. In commit mode it returns once the
+// tx has been included in a block, in sync and async modes it returns once the tx has been
+// broadcast and the result is logged when the tx is included. The account sequence is tracked
+// locally, so several txs can be sent concurrently and be included in the same block.
+func (src *Chain) SendMsgs(ctx context.Context, datagrams []sdk.Msg) (sdk.TxResponse, error) {
+	return src.sendMsgs(ctx, datagrams, src.waitsForCommit())
+}
+
+// sendMsgs signs and broadcasts the msgs, if wait is true it returns once the tx has been
+// included in a block whatever the broadcast mode is
+func (src *Chain) sendMsgs(ctx context.Context, datagrams []sdk.Msg, wait bool) (res sdk.TxResponse, err error) {
+	mode := flags.BroadcastSync
+	if src.BroadcastMode == broadcastModeAsync {
+		mode = flags.BroadcastAsync
+	}
+
+	if res, err = src.signer.broadcast(ctx, src, datagrams, mode); err != nil || res.Code != 0 {
+		src.store.released(src, datagrams)
+		if !src.debug {
+			res.RawLog = ""
+		}
 		return res, err
 	}
-	return src.BroadcastTxCommit(out)
+
+	src.store.submitted(src, res.TxHash, datagrams)
+	if !wait {
+		go src.trackTx(ctx, res.TxHash, datagrams)
+		return res, nil
+	}
+
+	// if the relayer stops before the tx is found, the packets are left submitted to be resumed
+	if res, err = src.waitForTx(ctx, res.TxHash); err == nil || ctx.Err() == nil {
+		src.store.included(src, datagrams, res, err)
+	}
+	return res, err
 }
 
 // BuildAndSignTx takes messages and builds, signs and marshals a sdk.Tx to prepare it for broadcast
-func (src *Chain) BuildAndSignTx(datagram []sdk.Msg) ([]byte, error) {
+func (src *Chain) BuildAndSignTx(ctx context.Context, datagram []sdk.Msg) ([]byte, error) {
+	return src.buildAndSignTx(ctx, src.Key, src.MustGetAddress(), datagram)
+}
+
+// buildAndSignTx builds, signs and marshals a sdk.Tx signed by the key with the given name and address.
+// If SimulateGas is set the gas is estimated by simulating the tx, otherwise the configured Gas is used
+func (src *Chain) buildAndSignTx(ctx context.Context, keyName string, addr sdk.AccAddress, datagram []sdk.Msg) ([]byte, error) {
 	// Fetch account and sequence numbers for the account
-	acc, err := auth.NewAccountRetriever(src.Cdc, src).GetAccount(src.MustGetAddress())
+	acc, err := auth.NewAccountRetriever(src.Cdc, src.querier(ctx)).GetAccount(addr)
 	if err != nil {
 		return nil, err
 	}
+	return src.signTx(ctx, keyName, acc.GetAccountNumber(), acc.GetSequence(), datagram)
+}
 
+// signTx builds, signs and marshals a sdk.Tx signed by the key with the given name
+// using the given account number and sequence
+func (src *Chain) signTx(ctx context.Context, keyName string, accNum, seq uint64, datagram []sdk.Msg) ([]byte, error) {
 	defer src.UseSDKContext()()
+	txBldr := src.txBuilder(accNum, seq)
+
+	if src.SimulateGas {
+		gas, err := src.simulateGas(ctx, txBldr, datagram)
+		if err != nil {
+			return nil, err
+		}
+		txBldr = txBldr.WithGas(gas)
+	}
+
+	return txBldr.BuildAndSign(keyName, ckeys.DefaultKeyPass, datagram)
+}
+
+// txBuilder returns a TxBuilder for txs signed with the given account number and sequence
+func (src *Chain) txBuilder(accNum, seq uint64) auth.TxBuilder {
 	return auth.NewTxBuilder(
-		auth.DefaultTxEncoder(src.Amino.Codec), acc.GetAccountNumber(),
-		acc.GetSequence(), src.Gas, src.GasAdjustment, false, src.ChainID,
-		src.Memo, sdk.NewCoins(), src.getGasPrices()).WithKeybase(src.Keybase).
-		BuildAndSign(src.Key, ckeys.DefaultKeyPass, datagram)
+		auth.DefaultTxEncoder(src.Amino.Codec), accNum,
+		seq, src.Gas, src.GasAdjustment, false, src.ChainID,
+		src.Memo, sdk.NewCoins(), src.getGasPrices()).WithKeybase(src.Keybase)
+}
+
+// EstimateGas simulates a tx with the msgs, signed by the configured key, and returns
+// the gas it is estimated to use with GasAdjustment applied, capped at MaxGas if it is set
+func (src *Chain) EstimateGas(ctx context.Context, msgs []sdk.Msg) (uint64, error) {
+	acc, err := auth.NewAccountRetriever(src.Cdc, src.querier(ctx)).GetAccount(src.MustGetAddress())
+	if err != nil {
+		return 0, err
+	}
+
+	defer src.UseSDKContext()()
+	return src.simulateGas(ctx, src.txBuilder(acc.GetAccountNumber(), acc.GetSequence()), msgs)
+}
+
+// SetDryRun sets whether the chain logs the txs it would send, with their
+// estimated gas, instead of broadcasting them
+func (src *Chain) SetDryRun(dryRun bool) {
+	src.dryRun = dryRun
+}
+
+// SetMaxClockDrift sets the max clock drift allowed by the clients created on the chain,
+// it is set from the path being relayed over
+func (src *Chain) SetMaxClockDrift(drift time.Duration) {
+	src.maxClockDrift = drift
+}
+
+// GetMaxClockDrift returns the max clock drift allowed by the clients created on the chain
+func (src *Chain) GetMaxClockDrift() time.Duration {
+	if src.maxClockDrift == 0 {
+		return defaultMaxClockDrift
+	}
+	return src.maxClockDrift
+}
+
+// simulateGas simulates the tx and returns the gas used with GasAdjustment applied,
+// capped at MaxGas if it is set
+func (src *Chain) simulateGas(ctx context.Context, txBldr auth.TxBuilder, datagram []sdk.Msg) (uint64, error) {
+	txBytes, err := txBldr.BuildTxForSim(datagram)
+	if err != nil {
+		return 0, err
+	}
+
+	adjustment := src.GasAdjustment
+	if adjustment == 0 {
+		adjustment = flags.DefaultGasAdjustment
+	}
+
+	_, gas, err := authclient.CalculateGas(src.querier(ctx).QueryWithData, src.Amino.Codec, txBytes, adjustment)
+	if err != nil {
+		return 0, fmt.Errorf("failed to simulate tx: %w", err)
+	}
+
+	if src.MaxGas != 0 && gas > src.MaxGas {
+		if src.debug {
+			src.Log(fmt.Sprintf("- [%s] -> simulated gas(%d) is over max-gas, using max-gas(%d)", src.ChainID, gas, src.MaxGas))
+		}
+		gas = src.MaxGas
+	}
+	return gas, nil
 }
 
 // BroadcastTxCommit takes the marshaled transaction bytes and broadcasts them
@@ -254,21 +431,18 @@ func (src *Chain) Error(err error) {
 	src.logger.Error(fmt.Sprintf("%s: err(%s)", src.ChainID, err.Error()))
 }
 
-// Start the client service
+// Start the client service, it is not an error if the client
+// has already been started by another path using this chain
 func (src *Chain) Start() error {
-	return src.Client.Start()
-}
-
-// Subscribe returns channel of events given a query
-func (src *Chain) Subscribe(query string) (<-chan ctypes.ResultEvent, context.CancelFunc, error) {
-	suffix, err := GenerateRandomString(8)
-	if err != nil {
-		return nil, nil, err
+	if err := src.Client.Start(); err != nil && err != service.ErrAlreadyStarted {
+		return err
 	}
+	return nil
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	eventChan, err := src.Client.Subscribe(ctx, fmt.Sprintf("%s-subscriber-%s", src.ChainID, suffix), query)
-	return eventChan, cancel, err
+// Subscribe returns channel of events given a query and a func that unsubscribes from them
+func (src *Chain) Subscribe(ctx context.Context, query string) (<-chan ctypes.ResultEvent, func(), error) {
+	return subscribe(ctx, src.Client, src.ChainID, query)
 }
 
 // KeysDir returns the path to the keys for this chain
@@ -280,6 +454,10 @@ func liteDir(home string) string {
 	return path.Join(home, "lite")
 }
 
+func storeDir(home string) string {
+	return path.Join(home, "store")
+}
+
 // GetAddress returns the sdk.AccAddress associated with the configred key
 func (src *Chain) GetAddress() (sdk.AccAddress, error) {
 	if src.address != nil {
@@ -343,6 +521,18 @@ func (src *Chain) Update(key, value string) (out *Chain, err error) {
 			return
 		}
 		out.RPCAddr = value
+	case "rpc-addrs":
+		var addrs []string
+		if addrs, err = parseRPCAddrs(value); err != nil {
+			return
+		}
+		out.RPCAddrs = addrs
+	case "witnesses":
+		var witnesses []string
+		if witnesses, err = parseRPCAddrs(value); err != nil {
+			return
+		}
+		out.Witnesses = witnesses
 	case "account-prefix":
 		out.AccountPrefix = value
 	case "gas":
@@ -352,11 +542,37 @@ func (src *Chain) Update(key, value string) (out *Chain, err error) {
 			return
 		}
 		out.Gas = gas
+	case "gas-adjustment":
+		var adj float64
+		adj, err = strconv.ParseFloat(value, 64)
+		if err != nil {
+			return
+		}
+		out.GasAdjustment = adj
 	case "gas-prices":
 		if _, err = sdk.ParseDecCoins(value); err != nil {
 			return
 		}
 		out.GasPrices = value
+	case "simulate-gas":
+		var sim bool
+		sim, err = strconv.ParseBool(value)
+		if err != nil {
+			return
+		}
+		out.SimulateGas = sim
+	case "max-gas":
+		var gas uint64
+		gas, err = strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return
+		}
+		out.MaxGas = gas
+	case "broadcast-mode":
+		if err = validateBroadcastMode(value); err != nil {
+			return
+		}
+		out.BroadcastMode = value
 	case "default-denom":
 		out.DefaultDenom = value
 	case "memo":
@@ -403,14 +619,14 @@ func (src *Chain) Print(toPrint interface{}, text, indent bool) error {
 }
 
 // SendAndPrint sends a transaction and prints according to the passed args
-func (src *Chain) SendAndPrint(txs []sdk.Msg, text, indent bool) (err error) {
+func (src *Chain) SendAndPrint(ctx context.Context, txs []sdk.Msg, text, indent bool) (err error) {
 	if src.debug {
 		if err = src.Print(txs, text, indent); err != nil {
 			return err
 		}
 	}
 	// SendAndPrint sends the transaction with printing options from the CLI
-	res, err := src.SendMsgs(txs)
+	res, err := src.SendMsgs(ctx, txs)
 	if err != nil {
 		return err
 	}
@@ -458,7 +674,11 @@ func (c Chains) Gets(chainIDs ...string) (map[string]*Chain, error) {
 
 // GetRPCPort returns the port configured for the chain
 func (src *Chain) GetRPCPort() string {
-	u, _ := url.Parse(src.RPCAddr)
+	addrs := src.GetRPCAddrs()
+	if len(addrs) == 0 {
+		return ""
+	}
+	u, _ := url.Parse(addrs[0])
 	return u.Port()
 }
 
@@ -496,8 +716,12 @@ func (src *Chain) GetTimeout() time.Duration {
 }
 
 // StatusErr returns err unless the chain is ready to go
-func (src *Chain) StatusErr() error {
-	stat, err := src.Client.Status()
+func (src *Chain) StatusErr(ctx context.Context) error {
+	var stat *ctypes.ResultStatus
+	err := rpcCall(ctx, func() (err error) {
+		stat, err = src.Client.Status()
+		return err
+	})
 	switch {
 	case err != nil:
 		return err
@@ -1,76 +1,27 @@
 package relayer
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	connState "github.com/cosmos/cosmos-sdk/x/ibc/03-connection/exported"
 	chanState "github.com/cosmos/cosmos-sdk/x/ibc/04-channel/exported"
+	chanTypes "github.com/cosmos/cosmos-sdk/x/ibc/04-channel/types"
 )
 
-// CreateChannel runs the channel creation messages on timeout until they pass
-// TODO: add max retries or something to this function
-func (src *Chain) CreateChannel(dst *Chain, ordered bool, to time.Duration) error {
-	var order chanState.Order
-	if ordered {
-		order = chanState.ORDERED
-	} else {
-		order = chanState.UNORDERED
-	}
-
-	ticker := time.NewTicker(to)
-	failures := 0
-	for ; true; <-ticker.C {
-		chanSteps, err := src.CreateChannelStep(dst, order)
-		if err != nil {
-			return err
-		}
-
-		if !chanSteps.Ready() {
-			break
-		}
-
-		chanSteps.Send(src, dst)
-
-		switch {
-		// In the case of success and this being the last transaction
-		// debug logging, log created connection and break
-		case chanSteps.success && chanSteps.last:
-			chans, err := QueryChannelPair(src, dst, 0, 0)
-			if err != nil {
-				return err
-			}
-			if src.debug {
-				logChannelStates(src, dst, chans)
-			}
-			src.Log(fmt.Sprintf("★ Channel created: [%s]chan{%s}port{%s} -> [%s]chan{%s}port{%s}",
-				src.ChainID, src.PathEnd.ChannelID, src.PathEnd.PortID,
-				dst.ChainID, dst.PathEnd.ChannelID, dst.PathEnd.PortID))
-			return nil
-		// In the case of success, reset the failures counter
-		case chanSteps.success:
-			failures = 0
-			continue
-		// In the case of failure, increment the failures counter and exit if this is the 3rd failure
-		case !chanSteps.success:
-			failures++
-			if failures > 2 {
-				return fmt.Errorf("! Channel failed: [%s]chan{%s}port{%s} -> [%s]chan{%s}port{%s}",
-					src.ChainID, src.PathEnd.ChannelID, src.PathEnd.PortID,
-					dst.ChainID, dst.PathEnd.ChannelID, dst.PathEnd.PortID)
-			}
-		}
-	}
-
-	return nil
+// CreateChannel runs the channel handshake until the channel is open, retrying failed steps
+func (src *Chain) CreateChannel(ctx context.Context, dst *Chain, ordered bool, to time.Duration) error {
+	return NewLinker(to).createChannel(ctx, src, dst, ordered)
 }
 
 // CreateChannelStep returns the next set of messages for creating a channel with given
 // identifiers between chains src and dst. If the handshake hasn't started, then CreateChannelStep
 // will begin the handshake on the src chain
-func (src *Chain) CreateChannelStep(dst *Chain, ordering chanState.Order) (*RelayMsgs, error) {
+func (src *Chain) CreateChannelStep(ctx context.Context, dst *Chain, ordering chanState.Order) (*RelayMsgs, error) {
 	var (
-		out        = &RelayMsgs{Src: []sdk.Msg{}, Dst: []sdk.Msg{}, last: false}
+		out        = &RelayMsgs{Src: []sdk.Msg{}, Dst: []sdk.Msg{}, last: false, commit: true}
 		scid, dcid = src.ChainID, dst.ChainID
 	)
 
@@ -82,15 +33,34 @@ func (src *Chain) CreateChannelStep(dst *Chain, ordering chanState.Order) (*Rela
 		return nil, dst.ErrCantSetPath(err)
 	}
 
-	hs, err := UpdatesWithHeaders(src, dst)
+	hs, err := UpdatesWithHeaders(ctx, src, dst)
 	if err != nil {
 		return nil, err
 	}
 
-	chans, err := QueryChannelPair(src, dst, hs[scid].Height-1, hs[dcid].Height-1)
+	// Every step after `chanOpenInit` needs the connection to be open on both ends
+	conn, err := QueryConnectionPair(ctx, src, dst, 0, 0)
 	if err != nil {
 		return nil, err
 	}
+	for _, c := range []*Chain{src, dst} {
+		if state := conn[c.ChainID].Connection.Connection.State; state != connState.OPEN {
+			return nil, fmt.Errorf("%w: [%s]conn{%s} is %s, open the connection with 'rly tx connection'",
+				errHandshakeBlocked, c.ChainID, c.PathEnd.ConnectionID, state)
+		}
+	}
+
+	chans, err := QueryChannelPair(ctx, src, dst, hs[scid].Height-1, hs[dcid].Height-1)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = checkChannelEnd(src, dst, chans[scid].Channel.Channel, ordering); err != nil {
+		return nil, err
+	}
+	if err = checkChannelEnd(dst, src, chans[dcid].Channel.Channel, ordering); err != nil {
+		return nil, err
+	}
 
 	switch {
 	// Handshake hasn't been started on src or dst, relay `chanOpenInit` to src
@@ -102,8 +72,10 @@ func (src *Chain) CreateChannelStep(dst *Chain, ordering chanState.Order) (*Rela
 			src.PathEnd.ChanInit(dst.PathEnd, src.MustGetAddress()),
 		)
 
-	// Handshake has started on dst (1 step done), relay `chanOpenTry` and `updateClient` to src
-	case chans[scid].Channel.Channel.State == chanState.UNINITIALIZED && chans[dcid].Channel.Channel.State == chanState.INIT:
+	// Handshake has started on dst (1 step done), relay `chanOpenTry` and `updateClient` to src. This
+	// also resolves crossing hellos where both ends are INIT, as src accepts a try over its INIT end
+	case (chans[scid].Channel.Channel.State == chanState.UNINITIALIZED || chans[scid].Channel.Channel.State == chanState.INIT) &&
+		chans[dcid].Channel.Channel.State == chanState.INIT:
 		if src.debug {
 			logChannelStates(src, dst, chans)
 		}
@@ -132,8 +104,10 @@ func (src *Chain) CreateChannelStep(dst *Chain, ordering chanState.Order) (*Rela
 			dst.PathEnd.ChanAck(chans[scid], dst.MustGetAddress()),
 		)
 
-	// Handshake has started on dst (2 steps done), relay `chanOpenAck` and `updateClient` to src
-	case chans[scid].Channel.Channel.State == chanState.INIT && chans[dcid].Channel.Channel.State == chanState.TRYOPEN:
+	// Handshake has started on dst (2 steps done), relay `chanOpenAck` and `updateClient` to src. This
+	// also resolves both ends having tried after crossing hellos, as src accepts an ack over its TRYOPEN end
+	case (chans[scid].Channel.Channel.State == chanState.INIT || chans[scid].Channel.Channel.State == chanState.TRYOPEN) &&
+		chans[dcid].Channel.Channel.State == chanState.TRYOPEN:
 		if src.debug {
 			logChannelStates(src, dst, chans)
 		}
@@ -163,18 +137,61 @@ func (src *Chain) CreateChannelStep(dst *Chain, ordering chanState.Order) (*Rela
 			dst.PathEnd.ChanConfirm(chans[scid], dst.MustGetAddress()),
 		)
 		out.last = true
+
+	// Handshake is complete, there is nothing to relay
+	case chans[scid].Channel.Channel.State == chanState.OPEN && chans[dcid].Channel.Channel.State == chanState.OPEN:
+
+	// Closed channels can't be reopened
+	case chans[scid].Channel.Channel.State == chanState.CLOSED || chans[dcid].Channel.Channel.State == chanState.CLOSED:
+		return nil, channelStuckErr(src, dst, chans,
+			"a closed channel can't be reopened, configure new channel identifiers for the path")
+
+	// The remaining pairs are left by a handshake on one end that the other end
+	// never saw, so the channel ends don't belong to the same handshake
+	default:
+		return nil, channelStuckErr(src, dst, chans,
+			"the ends can't be from the same handshake, configure new channel identifiers for the path")
 	}
 
 	return out, nil
 }
 
+// checkChannelEnd returns an error if the channel end on c exists but wasn't
+// opened for the path, as the handshake can't be completed with it
+func checkChannelEnd(c, counterparty *Chain, end chanTypes.Channel, ordering chanState.Order) error {
+	if end.State == chanState.UNINITIALIZED {
+		return nil
+	}
+
+	switch {
+	case len(end.ConnectionHops) != 1 || end.ConnectionHops[0] != c.PathEnd.ConnectionID:
+		return fmt.Errorf("channel %w, [%s]chan{%s}port{%s} is %s on conn%v, but the path uses conn{%s}: configure new channel identifiers for the path",
+			errHandshakeStuck, c.ChainID, c.PathEnd.ChannelID, c.PathEnd.PortID, end.State, end.ConnectionHops, c.PathEnd.ConnectionID)
+	case end.Counterparty.ChannelID != counterparty.PathEnd.ChannelID || end.Counterparty.PortID != counterparty.PathEnd.PortID:
+		return fmt.Errorf("channel %w, [%s]chan{%s}port{%s} is %s with counterparty [%s]chan{%s}port{%s}, but the path uses chan{%s}port{%s}: configure new channel identifiers for the path",
+			errHandshakeStuck, c.ChainID, c.PathEnd.ChannelID, c.PathEnd.PortID, end.State, counterparty.ChainID, end.Counterparty.ChannelID,
+			end.Counterparty.PortID, counterparty.PathEnd.ChannelID, counterparty.PathEnd.PortID)
+	case end.Ordering != ordering:
+		return fmt.Errorf("channel %w, [%s]chan{%s}port{%s} is %s and %s, but the path is %s: configure new channel identifiers for the path",
+			errHandshakeStuck, c.ChainID, c.PathEnd.ChannelID, c.PathEnd.PortID, end.State, end.Ordering, ordering)
+	}
+	return nil
+}
+
+// channelStuckErr explains why the channel handshake can't continue from the current states
+func channelStuckErr(src, dst *Chain, chans map[string]chanTypes.ChannelResponse, reason string) error {
+	return fmt.Errorf("channel %w, [%s]chan{%s} is %s and [%s]chan{%s} is %s: %s",
+		errHandshakeStuck, src.ChainID, src.PathEnd.ChannelID, chans[src.ChainID].Channel.Channel.State,
+		dst.ChainID, dst.PathEnd.ChannelID, chans[dst.ChainID].Channel.Channel.State, reason)
+}
+
 // CloseChannel runs the channel closing messages on timeout until they pass
 // TODO: add max retries or something to this function
-func (src *Chain) CloseChannel(dst *Chain, to time.Duration) error {
+func (src *Chain) CloseChannel(ctx context.Context, dst *Chain, to time.Duration) error {
 
 	ticker := time.NewTicker(to)
 	for ; true; <-ticker.C {
-		closeSteps, err := src.CloseChannelStep(dst)
+		closeSteps, err := src.CloseChannelStep(ctx, dst)
 		if err != nil {
 			return err
 		}
@@ -183,8 +200,15 @@ func (src *Chain) CloseChannel(dst *Chain, to time.Duration) error {
 			break
 		}
 
-		if closeSteps.Send(src, dst); closeSteps.success && closeSteps.last {
-			chans, err := QueryChannelPair(src, dst, 0, 0)
+		closeSteps.Send(ctx, src, dst)
+
+		// in a dry run nothing is committed, so the next step can't be built
+		if src.dryRun {
+			return nil
+		}
+
+		if closeSteps.success && closeSteps.last {
+			chans, err := QueryChannelPair(ctx, src, dst, 0, 0)
 			if err != nil {
 				return err
 			}
@@ -203,9 +227,9 @@ func (src *Chain) CloseChannel(dst *Chain, to time.Duration) error {
 // CloseChannelStep returns the next set of messages for closing a channel with given
 // identifiers between chains src and dst. If the closing handshake hasn't started, then CloseChannelStep
 // will begin the handshake on the src chain
-func (src *Chain) CloseChannelStep(dst *Chain) (*RelayMsgs, error) {
+func (src *Chain) CloseChannelStep(ctx context.Context, dst *Chain) (*RelayMsgs, error) {
 	var (
-		out        = &RelayMsgs{Src: []sdk.Msg{}, Dst: []sdk.Msg{}, last: false}
+		out        = &RelayMsgs{Src: []sdk.Msg{}, Dst: []sdk.Msg{}, last: false, commit: true}
 		scid, dcid = src.ChainID, dst.ChainID
 	)
 
@@ -217,12 +241,12 @@ func (src *Chain) CloseChannelStep(dst *Chain) (*RelayMsgs, error) {
 		return nil, dst.ErrCantSetPath(err)
 	}
 
-	hs, err := UpdatesWithHeaders(src, dst)
+	hs, err := UpdatesWithHeaders(ctx, src, dst)
 	if err != nil {
 		return nil, err
 	}
 
-	chans, err := QueryChannelPair(src, dst, hs[scid].Height-1, hs[dcid].Height-1)
+	chans, err := QueryChannelPair(ctx, src, dst, hs[scid].Height-1, hs[dcid].Height-1)
 	if err != nil {
 		return nil, err
 	}
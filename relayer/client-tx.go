@@ -1,54 +1,150 @@
 package relayer
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
-	clientTypes "github.com/cosmos/cosmos-sdk/x/ibc/02-client/types"
+	tmclient "github.com/cosmos/cosmos-sdk/x/ibc/07-tendermint/types"
 )
 
+// errTrustingPeriod is returned when a client would be created with a trusting period that
+// isn't shorter than the unbonding period, as the client wouldn't be safe from long range attacks
+var errTrustingPeriod = errors.New("trusting period must be shorter than the unbonding period")
+
 // CreateClients creates clients for src on dst and dst on src given the configured paths
-func (src *Chain) CreateClients(dst *Chain) (err error) {
-	clients := &RelayMsgs{Src: []sdk.Msg{}, Dst: []sdk.Msg{}}
+func (src *Chain) CreateClients(ctx context.Context, dst *Chain) error {
+	return NewLinker(defaultLinkBackoff).createClients(ctx, src, dst)
+}
+
+// CreateClientsStep returns the msgs to create the clients for src on dst and dst
+// on src that don't exist yet, it isn't Ready once both clients exist
+func (src *Chain) CreateClientsStep(ctx context.Context, dst *Chain) (*RelayMsgs, error) {
+	clients := &RelayMsgs{Src: []sdk.Msg{}, Dst: []sdk.Msg{}, last: true, commit: true}
 
 	// Create client for dst on src if it doesn't exist
-	var srcCs, dstCs *clientTypes.StateResponse
-	if srcCs, err = src.QueryClientState(); err != nil {
-		return err
+	if srcCs, err := src.QueryClientState(ctx); err != nil {
+		return nil, err
 	} else if srcCs == nil {
-		dstH, err := dst.UpdateLiteWithHeader()
+		msg, err := src.CreateClientMsg(ctx, dst)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		if src.debug {
-			src.logCreateClient(dst, dstH.GetHeight())
-		}
-		clients.Src = append(clients.Src, src.PathEnd.CreateClient(dstH, src.GetTrustingPeriod(), src.MustGetAddress()))
+		clients.Src = append(clients.Src, msg)
 	}
-	// TODO: maybe log something here that the client has been created?
 
 	// Create client for src on dst if it doesn't exist
-	if dstCs, err = dst.QueryClientState(); err != nil {
-		return err
+	if dstCs, err := dst.QueryClientState(ctx); err != nil {
+		return nil, err
 	} else if dstCs == nil {
-		srcH, err := src.UpdateLiteWithHeader()
+		msg, err := dst.CreateClientMsg(ctx, src)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		if dst.debug {
-			dst.logCreateClient(src, srcH.GetHeight())
+		clients.Dst = append(clients.Dst, msg)
+	}
+
+	return clients, nil
+}
+
+// CreateClientMsg returns the msg to create the client for dst on src. The client uses dst's
+// trusting period and its unbonding period, which is queried from dst, and the client isn't
+// created if the trusting period isn't shorter than the unbonding period.
+func (src *Chain) CreateClientMsg(ctx context.Context, dst *Chain) (sdk.Msg, error) {
+	unbonding, err := dst.QueryUnbondingPeriod(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if tp := dst.GetTrustingPeriod(); tp >= unbonding {
+		return nil, fmt.Errorf("%w, [%s] has a trusting-period of %s and an unbonding period of %s",
+			errTrustingPeriod, dst.ChainID, tp, unbonding)
+	}
+
+	dstH, err := dst.UpdateLiteWithHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if src.debug {
+		src.logCreateClient(dst, dstH.GetHeight(), unbonding)
+	}
+	return src.PathEnd.CreateClient(dstH, dst.GetTrustingPeriod(), unbonding, src.GetMaxClockDrift(), src.MustGetAddress()), nil
+}
+
+// UpdateClients updates the clients on both chains with the latest header of their counterparty
+func (src *Chain) UpdateClients(ctx context.Context, dst *Chain) error {
+	return RefreshClients(ctx, src, dst, 0)
+}
+
+// RefreshClients updates the client on each chain once more than threshold, a fraction of
+// the client's trusting period, has passed since the client's latest header. A threshold
+// of 0 updates both clients.
+func RefreshClients(ctx context.Context, src, dst *Chain, threshold float64) error {
+	clients := &RelayMsgs{Src: []sdk.Msg{}, Dst: []sdk.Msg{}}
+
+	// Update the client for dst on src if it is due
+	srcDue, err := src.clientRefreshDue(ctx, threshold)
+	if err != nil {
+		return err
+	} else if srcDue {
+		dstH, err := dst.UpdateLiteWithHeader(ctx)
+		if err != nil {
+			return err
 		}
-		clients.Dst = append(clients.Dst, dst.PathEnd.CreateClient(srcH, dst.GetTrustingPeriod(), dst.MustGetAddress()))
+		clients.Src = append(clients.Src, src.PathEnd.UpdateClient(dstH, src.MustGetAddress()))
 	}
-	// TODO: maybe log something here that the client has been created?
 
-	// Send msgs to both chains
-	if clients.Ready() {
-		if clients.Send(src, dst); clients.success {
-			src.Log(fmt.Sprintf("★ Clients created: [%s]client(%s) and [%s]client(%s)",
-				src.ChainID, src.PathEnd.ClientID, dst.ChainID, dst.PathEnd.ClientID))
+	// Update the client for src on dst if it is due
+	dstDue, err := dst.clientRefreshDue(ctx, threshold)
+	if err != nil {
+		return err
+	} else if dstDue {
+		srcH, err := src.UpdateLiteWithHeader(ctx)
+		if err != nil {
+			return err
 		}
+		clients.Dst = append(clients.Dst, dst.PathEnd.UpdateClient(srcH, dst.MustGetAddress()))
+	}
+
+	if !clients.Ready() {
+		return nil
 	}
 
+	if clients.Send(ctx, src, dst); !clients.success {
+		return fmt.Errorf("failed to update clients: [%s]client(%s) and [%s]client(%s)",
+			src.ChainID, src.PathEnd.ClientID, dst.ChainID, dst.PathEnd.ClientID)
+	}
+	if !src.dryRun {
+		src.Log(fmt.Sprintf("★ Clients updated: [%s]client(%s) and [%s]client(%s)",
+			src.ChainID, src.PathEnd.ClientID, dst.ChainID, dst.PathEnd.ClientID))
+	}
 	return nil
 }
+
+// clientRefreshDue returns true if more than threshold of the client's trusting
+// period has passed since the timestamp of the client's latest header
+func (c *Chain) clientRefreshDue(ctx context.Context, threshold float64) (bool, error) {
+	csRes, err := c.QueryClientState(ctx)
+	switch {
+	case err != nil:
+		return false, err
+	case csRes == nil:
+		return false, fmt.Errorf("client %s not found on %s", c.PathEnd.ClientID, c.ChainID)
+	case threshold == 0:
+		return true, nil
+	}
+
+	cs, ok := csRes.ClientState.(tmclient.ClientState)
+	if !ok {
+		return false, fmt.Errorf("client %s on %s is not a tendermint client", c.PathEnd.ClientID, c.ChainID)
+	}
+
+	elapsed := time.Since(cs.GetLatestTimestamp())
+	due := elapsed >= time.Duration(threshold*float64(cs.TrustingPeriod))
+	if due && c.debug {
+		c.Log(fmt.Sprintf("- [%s]client(%s)@{%d} last updated %s ago, trusting period %s",
+			c.ChainID, c.PathEnd.ClientID, cs.GetLatestHeight(), elapsed.Round(time.Second), cs.TrustingPeriod))
+	}
+	return due, nil
+}
@@ -1,69 +1,36 @@
 package relayer
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	connState "github.com/cosmos/cosmos-sdk/x/ibc/03-connection/exported"
+	connTypes "github.com/cosmos/cosmos-sdk/x/ibc/03-connection/types"
 )
 
-// CreateConnection runs the connection creation messages on timeout until they pass
-// TODO: add max retries or something to this function
-func (src *Chain) CreateConnection(dst *Chain, to time.Duration) error {
-	ticker := time.NewTicker(to)
-	failed := 0
-	for ; true; <-ticker.C {
-		connSteps, err := src.CreateConnectionStep(dst)
-		if err != nil {
-			return err
-		}
-
-		if !connSteps.Ready() {
-			break
-		}
+var (
+	// errHandshakeBlocked is returned when a handshake can't start until
+	// the objects it is built on have been created
+	errHandshakeBlocked = errors.New("handshake is blocked")
 
-		connSteps.Send(src, dst)
-
-		switch {
-		// In the case of success and this being the last transaction
-		// debug logging, log created connection and break
-		case connSteps.success && connSteps.last:
-			if src.debug {
-				conns, err := QueryConnectionPair(src, dst, 0, 0)
-				if err != nil {
-					return err
-				}
-				logConnectionStates(src, dst, conns)
-			}
-
-			src.Log(fmt.Sprintf("★ Connection created: [%s]client{%s}conn{%s} -> [%s]client{%s}conn{%s}",
-				src.ChainID, src.PathEnd.ClientID, src.PathEnd.ConnectionID,
-				dst.ChainID, dst.PathEnd.ClientID, dst.PathEnd.ConnectionID))
-			return nil
-		// In the case of success, reset the failures counter
-		case connSteps.success:
-			failed = 0
-			continue
-		// In the case of failure, increment the failures counter and exit if this is the 3rd failure
-		case !connSteps.success:
-			failed++
-			if failed > 2 {
-				return fmt.Errorf("! Connection failed: [%s]client{%s}conn{%s} -> [%s]client{%s}conn{%s}",
-					src.ChainID, src.PathEnd.ClientID, src.PathEnd.ConnectionID,
-					dst.ChainID, dst.PathEnd.ClientID, dst.PathEnd.ConnectionID)
-			}
-		}
-	}
+	// errHandshakeStuck is returned when the ends of a handshake are in
+	// states it can't be completed from
+	errHandshakeStuck = errors.New("handshake can't be completed")
+)
 
-	return nil
+// CreateConnection runs the connection handshake until the connection is open, retrying failed steps
+func (src *Chain) CreateConnection(ctx context.Context, dst *Chain, to time.Duration) error {
+	return NewLinker(to).createConnection(ctx, src, dst)
 }
 
 // CreateConnectionStep returns the next set of messags for creating a channel
 // with the given identifier between chains src and dst. If handshake hasn't started,
 // CreateConnetionStep will start the handshake on src
-func (src *Chain) CreateConnectionStep(dst *Chain) (*RelayMsgs, error) {
-	out := &RelayMsgs{Src: []sdk.Msg{}, Dst: []sdk.Msg{}, last: false}
+func (src *Chain) CreateConnectionStep(ctx context.Context, dst *Chain) (*RelayMsgs, error) {
+	out := &RelayMsgs{Src: []sdk.Msg{}, Dst: []sdk.Msg{}, last: false, commit: true}
 
 	if err := src.PathEnd.Validate(); err != nil {
 		return nil, src.ErrCantSetPath(err)
@@ -73,7 +40,7 @@ func (src *Chain) CreateConnectionStep(dst *Chain) (*RelayMsgs, error) {
 		return nil, dst.ErrCantSetPath(err)
 	}
 
-	hs, err := UpdatesWithHeaders(src, dst)
+	hs, err := UpdatesWithHeaders(ctx, src, dst)
 	if err != nil {
 		return nil, err
 	}
@@ -83,21 +50,23 @@ func (src *Chain) CreateConnectionStep(dst *Chain) (*RelayMsgs, error) {
 	// Query Connection data from src and dst
 	// NOTE: We query connection at height - 1 because of the way tendermint returns
 	// proofs the commit for height n is contained in the header of height n + 1
-	conn, err := QueryConnectionPair(src, dst, hs[scid].Height-1, hs[dcid].Height-1)
+	conn, err := QueryConnectionPair(ctx, src, dst, hs[scid].Height-1, hs[dcid].Height-1)
 	if err != nil {
 		return nil, err
 	}
 
 	// NOTE: We query connection at height - 1 because of the way tendermint returns
 	// proofs the commit for height n is contained in the header of height n + 1
-	cs, err := QueryClientStatePair(src, dst)
+	cs, err := QueryClientStatePair(ctx, src, dst)
 	if err != nil {
 		return nil, err
 	}
 
-	// TODO: log these heights or something about client state? debug?
-	if cs[scid] == nil || cs[dcid] == nil {
-		return nil, err
+	for _, c := range []*Chain{src, dst} {
+		if cs[c.ChainID] == nil {
+			return nil, fmt.Errorf("%w: [%s]client{%s} doesn't exist, create the clients with 'rly tx clients'",
+				errHandshakeBlocked, c.ChainID, c.PathEnd.ClientID)
+		}
 	}
 
 	// Store the heights
@@ -105,11 +74,18 @@ func (src *Chain) CreateConnectionStep(dst *Chain) (*RelayMsgs, error) {
 
 	// NOTE: We query connection at height - 1 because of the way tendermint returns
 	// proofs the commit for height n is contained in the header of height n + 1
-	cons, err := QueryClientConsensusStatePair(src, dst, hs[scid].Height-1, hs[dcid].Height-1, srcConsH, dstConsH)
+	cons, err := QueryClientConsensusStatePair(ctx, src, dst, hs[scid].Height-1, hs[dcid].Height-1, srcConsH, dstConsH)
 	if err != nil {
 		return nil, err
 	}
 
+	if err = checkConnectionEnd(src, dst, conn[scid].Connection.Connection); err != nil {
+		return nil, err
+	}
+	if err = checkConnectionEnd(dst, src, conn[dcid].Connection.Connection); err != nil {
+		return nil, err
+	}
+
 	switch {
 	// Handshake hasn't been started on src or dst, relay `connOpenInit` to src
 	case conn[scid].Connection.Connection.State == connState.UNINITIALIZED && conn[dcid].Connection.Connection.State == connState.UNINITIALIZED:
@@ -118,8 +94,10 @@ func (src *Chain) CreateConnectionStep(dst *Chain) (*RelayMsgs, error) {
 		}
 		out.Src = append(out.Src, src.PathEnd.ConnInit(dst.PathEnd, src.MustGetAddress()))
 
-	// Handshake has started on dst (1 stepdone), relay `connOpenTry` and `updateClient` on src
-	case conn[scid].Connection.Connection.State == connState.UNINITIALIZED && conn[dcid].Connection.Connection.State == connState.INIT:
+	// Handshake has started on dst (1 stepdone), relay `connOpenTry` and `updateClient` on src. This
+	// also resolves crossing hellos where both ends are INIT, as src accepts a try over its INIT end
+	case (conn[scid].Connection.Connection.State == connState.UNINITIALIZED || conn[scid].Connection.Connection.State == connState.INIT) &&
+		conn[dcid].Connection.Connection.State == connState.INIT:
 		if src.debug {
 			logConnectionStates(src, dst, conn)
 		}
@@ -179,7 +157,47 @@ func (src *Chain) CreateConnectionStep(dst *Chain) (*RelayMsgs, error) {
 			dst.PathEnd.ConnConfirm(conn[scid], dst.MustGetAddress()),
 		)
 		out.last = true
+
+	// Handshake is complete, there is nothing to relay
+	case conn[scid].Connection.Connection.State == connState.OPEN && conn[dcid].Connection.Connection.State == connState.OPEN:
+
+	// Both ends tried after crossing hellos, neither end can ack as `connOpenAck` needs an INIT end
+	case conn[scid].Connection.Connection.State == connState.TRYOPEN && conn[dcid].Connection.Connection.State == connState.TRYOPEN:
+		return nil, connectionStuckErr(src, dst, conn,
+			"both ends are TRYOPEN and neither can be acknowledged, configure new connection identifiers for the path")
+
+	// The remaining pairs are left by a handshake on one end that the other end
+	// never saw, so the connection ends don't belong to the same handshake
+	default:
+		return nil, connectionStuckErr(src, dst, conn,
+			"the ends can't be from the same handshake, configure new connection identifiers for the path")
 	}
 
 	return out, nil
 }
+
+// checkConnectionEnd returns an error if the connection end on c exists but wasn't
+// opened for the path, as the handshake can't be completed with it
+func checkConnectionEnd(c, counterparty *Chain, end connTypes.ConnectionEnd) error {
+	if end.State == connState.UNINITIALIZED {
+		return nil
+	}
+
+	switch {
+	case end.ClientID != c.PathEnd.ClientID:
+		return fmt.Errorf("connection %w, [%s]conn{%s} is %s on client{%s}, but the path uses client{%s}: configure new connection identifiers for the path",
+			errHandshakeStuck, c.ChainID, c.PathEnd.ConnectionID, end.State, end.ClientID, c.PathEnd.ClientID)
+	case end.Counterparty.ClientID != counterparty.PathEnd.ClientID || end.Counterparty.ConnectionID != counterparty.PathEnd.ConnectionID:
+		return fmt.Errorf("connection %w, [%s]conn{%s} is %s with counterparty [%s]client{%s}conn{%s}, but the path uses client{%s}conn{%s}: configure new connection identifiers for the path",
+			errHandshakeStuck, c.ChainID, c.PathEnd.ConnectionID, end.State, counterparty.ChainID, end.Counterparty.ClientID, end.Counterparty.ConnectionID,
+			counterparty.PathEnd.ClientID, counterparty.PathEnd.ConnectionID)
+	}
+	return nil
+}
+
+// connectionStuckErr explains why the connection handshake can't continue from the current states
+func connectionStuckErr(src, dst *Chain, conn map[string]connTypes.ConnectionResponse, reason string) error {
+	return fmt.Errorf("connection %w, [%s]conn{%s} is %s and [%s]conn{%s} is %s: %s",
+		errHandshakeStuck, src.ChainID, src.PathEnd.ConnectionID, conn[src.ChainID].Connection.Connection.State,
+		dst.ChainID, dst.PathEnd.ConnectionID, conn[dst.ChainID].Connection.Connection.State, reason)
+}
@@ -0,0 +1,400 @@
+package relayer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	retry "github.com/avast/retry-go"
+	"github.com/tendermint/tendermint/libs/bytes"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/libs/service"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+	rpchttp "github.com/tendermint/tendermint/rpc/client/http"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	rpctypes "github.com/tendermint/tendermint/rpc/lib/types"
+	"github.com/tendermint/tendermint/types"
+)
+
+var (
+	// rpcRetryDelay and rpcRetryMaxDelay bound how long an endpoint that failed
+	// is skipped for before requests are sent to it again
+	rpcRetryDelay    = time.Second * 10
+	rpcRetryMaxDelay = time.Minute * 5
+)
+
+// rpcEndpoint is a node in a chain's rpc-addrs and its health
+type rpcEndpoint struct {
+	addr      string
+	client    *rpchttp.HTTP
+	failures  int
+	downUntil time.Time
+}
+
+// healthy returns true if the endpoint hasn't failed recently
+func (e *rpcEndpoint) healthy(now time.Time) bool {
+	return !now.Before(e.downUntil)
+}
+
+// failoverClient is an rpcclient.Client that sends each request to the first healthy
+// endpoint in the chain's rpc-addrs. If an endpoint can't be reached it is skipped,
+// with backoff, and the request is sent to the next one. As the endpoints are always
+// tried in order, the preferred endpoint is used again as soon as its backoff is over.
+type failoverClient struct {
+	*service.BaseService
+
+	chainID   string
+	logger    log.Logger
+	mu        sync.Mutex
+	endpoints []*rpcEndpoint
+	current   int
+
+	// subscribers maps each subscriber to the endpoint it subscribed with
+	subscribers map[string]*rpcEndpoint
+}
+
+var _ rpcclient.Client = (*failoverClient)(nil)
+
+// newFailoverClient returns a client for the given endpoints, in order of preference
+func newFailoverClient(chainID string, addrs []string, timeout time.Duration, logger log.Logger) (*failoverClient, error) {
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no rpc addresses configured for chain %s", chainID)
+	}
+
+	fc := &failoverClient{chainID: chainID, logger: logger, subscribers: make(map[string]*rpcEndpoint)}
+	for _, addr := range addrs {
+		client, err := newRPCClient(addr, timeout)
+		if err != nil {
+			return nil, fmt.Errorf("rpc address %s: %w", addr, err)
+		}
+		fc.endpoints = append(fc.endpoints, &rpcEndpoint{addr: addr, client: client})
+	}
+	fc.BaseService = service.NewBaseService(nil, "failoverClient", fc)
+	return fc, nil
+}
+
+// pick returns the most preferred healthy endpoint, or the one that will recover
+// soonest if none of them are healthy
+func (fc *failoverClient) pick() *rpcEndpoint {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	now, next := time.Now(), 0
+	for i, e := range fc.endpoints {
+		if e.healthy(now) {
+			next = i
+			break
+		}
+		if e.downUntil.Before(fc.endpoints[next].downUntil) {
+			next = i
+		}
+	}
+
+	if next != fc.current {
+		fc.logger.Info(fmt.Sprintf("- [%s] switching rpc endpoint from %s to %s", fc.chainID, fc.endpoints[fc.current].addr, fc.endpoints[next].addr))
+		fc.current = next
+	}
+	return fc.endpoints[next]
+}
+
+// failed marks the endpoint as unhealthy, backing off further with each consecutive failure
+func (fc *failoverClient) failed(e *rpcEndpoint, err error) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	delay := rpcRetryDelay << uint(e.failures)
+	if delay <= 0 || delay > rpcRetryMaxDelay {
+		delay = rpcRetryMaxDelay
+	}
+	e.failures++
+	e.downUntil = time.Now().Add(delay)
+	fc.logger.Error(fmt.Sprintf("%s: err(rpc endpoint %s failed, skipping it for %s: %s)", fc.chainID, e.addr, delay, err))
+}
+
+// succeeded marks the endpoint as healthy
+func (fc *failoverClient) succeeded(e *rpcEndpoint) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	e.failures, e.downUntil = 0, time.Time{}
+}
+
+// failedAddr marks the endpoint with the given address as unhealthy
+func (fc *failoverClient) failedAddr(addr string, err error) {
+	for _, e := range fc.endpoints {
+		if e.addr == addr {
+			fc.failed(e, err)
+			return
+		}
+	}
+}
+
+// Remote returns the address of the endpoint currently in use
+func (fc *failoverClient) Remote() string {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return fc.endpoints[fc.current].addr
+}
+
+// do calls f with each endpoint in turn until one of them can be reached
+func (fc *failoverClient) do(f func(*rpcEndpoint) error) (err error) {
+	for range fc.endpoints {
+		e := fc.pick()
+		if err = f(e); !isEndpointErr(err) {
+			fc.succeeded(e)
+			return err
+		}
+		fc.failed(e, err)
+	}
+	return err
+}
+
+// isEndpointErr returns true if err means the endpoint couldn't handle the request,
+// rather than the node returning an error for the request itself
+func isEndpointErr(err error) bool {
+	if err == nil {
+		return false
+	}
+	var rpcErr *rpctypes.RPCError
+	return !errors.As(err, &rpcErr)
+}
+
+// OnStart implements service.Service, the endpoints' websocket
+// connections are started when they are first subscribed with
+func (fc *failoverClient) OnStart() error {
+	return nil
+}
+
+// OnStop implements service.Service
+func (fc *failoverClient) OnStop() {
+	for _, e := range fc.endpoints {
+		if e.client.IsRunning() {
+			_ = e.client.Stop()
+		}
+	}
+}
+
+// Subscribe implements rpcclient.EventsClient
+func (fc *failoverClient) Subscribe(ctx context.Context, subscriber, query string, outCapacity ...int) (out <-chan ctypes.ResultEvent, err error) {
+	err = fc.do(func(e *rpcEndpoint) (err error) {
+		if err = e.client.Start(); err != nil && err != service.ErrAlreadyStarted {
+			return err
+		}
+		if out, err = e.client.Subscribe(ctx, subscriber, query, outCapacity...); err != nil {
+			return err
+		}
+
+		fc.mu.Lock()
+		fc.subscribers[subscriber] = e
+		fc.mu.Unlock()
+		return nil
+	})
+	return
+}
+
+// Unsubscribe implements rpcclient.EventsClient
+func (fc *failoverClient) Unsubscribe(ctx context.Context, subscriber, query string) error {
+	fc.mu.Lock()
+	e, ok := fc.subscribers[subscriber]
+	fc.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("subscriber %s not found", subscriber)
+	}
+	return e.client.Unsubscribe(ctx, subscriber, query)
+}
+
+// UnsubscribeAll implements rpcclient.EventsClient
+func (fc *failoverClient) UnsubscribeAll(ctx context.Context, subscriber string) error {
+	fc.mu.Lock()
+	e, ok := fc.subscribers[subscriber]
+	delete(fc.subscribers, subscriber)
+	fc.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("subscriber %s not found", subscriber)
+	}
+	return e.client.UnsubscribeAll(ctx, subscriber)
+}
+
+// ABCIInfo implements rpcclient.ABCIClient
+func (fc *failoverClient) ABCIInfo() (res *ctypes.ResultABCIInfo, err error) {
+	err = fc.do(func(e *rpcEndpoint) (err error) { res, err = e.client.ABCIInfo(); return })
+	return
+}
+
+// ABCIQuery implements rpcclient.ABCIClient
+func (fc *failoverClient) ABCIQuery(path string, data bytes.HexBytes) (res *ctypes.ResultABCIQuery, err error) {
+	err = fc.do(func(e *rpcEndpoint) (err error) { res, err = e.client.ABCIQuery(path, data); return })
+	return
+}
+
+// ABCIQueryWithOptions implements rpcclient.ABCIClient
+func (fc *failoverClient) ABCIQueryWithOptions(path string, data bytes.HexBytes, opts rpcclient.ABCIQueryOptions) (res *ctypes.ResultABCIQuery, err error) {
+	err = fc.do(func(e *rpcEndpoint) (err error) { res, err = e.client.ABCIQueryWithOptions(path, data, opts); return })
+	return
+}
+
+// BroadcastTxCommit implements rpcclient.ABCIClient
+func (fc *failoverClient) BroadcastTxCommit(tx types.Tx) (res *ctypes.ResultBroadcastTxCommit, err error) {
+	err = fc.do(func(e *rpcEndpoint) (err error) { res, err = e.client.BroadcastTxCommit(tx); return })
+	return
+}
+
+// BroadcastTxAsync implements rpcclient.ABCIClient
+func (fc *failoverClient) BroadcastTxAsync(tx types.Tx) (res *ctypes.ResultBroadcastTx, err error) {
+	err = fc.do(func(e *rpcEndpoint) (err error) { res, err = e.client.BroadcastTxAsync(tx); return })
+	return
+}
+
+// BroadcastTxSync implements rpcclient.ABCIClient
+func (fc *failoverClient) BroadcastTxSync(tx types.Tx) (res *ctypes.ResultBroadcastTx, err error) {
+	err = fc.do(func(e *rpcEndpoint) (err error) { res, err = e.client.BroadcastTxSync(tx); return })
+	return
+}
+
+// Block implements rpcclient.SignClient
+func (fc *failoverClient) Block(height *int64) (res *ctypes.ResultBlock, err error) {
+	err = fc.do(func(e *rpcEndpoint) (err error) { res, err = e.client.Block(height); return })
+	return
+}
+
+// BlockResults implements rpcclient.SignClient
+func (fc *failoverClient) BlockResults(height *int64) (res *ctypes.ResultBlockResults, err error) {
+	err = fc.do(func(e *rpcEndpoint) (err error) { res, err = e.client.BlockResults(height); return })
+	return
+}
+
+// Commit implements rpcclient.SignClient
+func (fc *failoverClient) Commit(height *int64) (res *ctypes.ResultCommit, err error) {
+	err = fc.do(func(e *rpcEndpoint) (err error) { res, err = e.client.Commit(height); return })
+	return
+}
+
+// Validators implements rpcclient.SignClient
+func (fc *failoverClient) Validators(height *int64, page, perPage int) (res *ctypes.ResultValidators, err error) {
+	err = fc.do(func(e *rpcEndpoint) (err error) { res, err = e.client.Validators(height, page, perPage); return })
+	return
+}
+
+// Tx implements rpcclient.SignClient
+func (fc *failoverClient) Tx(hash []byte, prove bool) (res *ctypes.ResultTx, err error) {
+	err = fc.do(func(e *rpcEndpoint) (err error) { res, err = e.client.Tx(hash, prove); return })
+	return
+}
+
+// TxSearch implements rpcclient.SignClient
+func (fc *failoverClient) TxSearch(query string, prove bool, page, perPage int, orderBy string) (res *ctypes.ResultTxSearch, err error) {
+	err = fc.do(func(e *rpcEndpoint) (err error) {
+		res, err = e.client.TxSearch(query, prove, page, perPage, orderBy)
+		return
+	})
+	return
+}
+
+// Genesis implements rpcclient.HistoryClient
+func (fc *failoverClient) Genesis() (res *ctypes.ResultGenesis, err error) {
+	err = fc.do(func(e *rpcEndpoint) (err error) { res, err = e.client.Genesis(); return })
+	return
+}
+
+// BlockchainInfo implements rpcclient.HistoryClient
+func (fc *failoverClient) BlockchainInfo(minHeight, maxHeight int64) (res *ctypes.ResultBlockchainInfo, err error) {
+	err = fc.do(func(e *rpcEndpoint) (err error) { res, err = e.client.BlockchainInfo(minHeight, maxHeight); return })
+	return
+}
+
+// Status implements rpcclient.StatusClient
+func (fc *failoverClient) Status() (res *ctypes.ResultStatus, err error) {
+	err = fc.do(func(e *rpcEndpoint) (err error) { res, err = e.client.Status(); return })
+	return
+}
+
+// NetInfo implements rpcclient.NetworkClient
+func (fc *failoverClient) NetInfo() (res *ctypes.ResultNetInfo, err error) {
+	err = fc.do(func(e *rpcEndpoint) (err error) { res, err = e.client.NetInfo(); return })
+	return
+}
+
+// DumpConsensusState implements rpcclient.NetworkClient
+func (fc *failoverClient) DumpConsensusState() (res *ctypes.ResultDumpConsensusState, err error) {
+	err = fc.do(func(e *rpcEndpoint) (err error) { res, err = e.client.DumpConsensusState(); return })
+	return
+}
+
+// ConsensusState implements rpcclient.NetworkClient
+func (fc *failoverClient) ConsensusState() (res *ctypes.ResultConsensusState, err error) {
+	err = fc.do(func(e *rpcEndpoint) (err error) { res, err = e.client.ConsensusState(); return })
+	return
+}
+
+// ConsensusParams implements rpcclient.NetworkClient
+func (fc *failoverClient) ConsensusParams(height *int64) (res *ctypes.ResultConsensusParams, err error) {
+	err = fc.do(func(e *rpcEndpoint) (err error) { res, err = e.client.ConsensusParams(height); return })
+	return
+}
+
+// Health implements rpcclient.NetworkClient
+func (fc *failoverClient) Health() (res *ctypes.ResultHealth, err error) {
+	err = fc.do(func(e *rpcEndpoint) (err error) { res, err = e.client.Health(); return })
+	return
+}
+
+// BroadcastEvidence implements rpcclient.EvidenceClient
+func (fc *failoverClient) BroadcastEvidence(ev types.Evidence) (res *ctypes.ResultBroadcastEvidence, err error) {
+	err = fc.do(func(e *rpcEndpoint) (err error) { res, err = e.client.BroadcastEvidence(ev); return })
+	return
+}
+
+// UnconfirmedTxs implements rpcclient.MempoolClient
+func (fc *failoverClient) UnconfirmedTxs(limit int) (res *ctypes.ResultUnconfirmedTxs, err error) {
+	err = fc.do(func(e *rpcEndpoint) (err error) { res, err = e.client.UnconfirmedTxs(limit); return })
+	return
+}
+
+// NumUnconfirmedTxs implements rpcclient.MempoolClient
+func (fc *failoverClient) NumUnconfirmedTxs() (res *ctypes.ResultUnconfirmedTxs, err error) {
+	err = fc.do(func(e *rpcEndpoint) (err error) { res, err = e.client.NumUnconfirmedTxs(); return })
+	return
+}
+
+// rpcCall calls f, returning early with ctx's error if ctx is done first. The rpc client
+// doesn't take a context, so f is left to finish in the background, bounded by the
+// client's timeout. f must only set variables that the caller reads when rpcCall
+// returns a nil error.
+func rpcCall(ctx context.Context, f func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	errc := make(chan error, 1)
+	go func() { errc <- f() }()
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// retryUntilDone stops retry.Do from retrying once ctx is done
+func retryUntilDone(ctx context.Context) retry.Option {
+	return retry.RetryIf(func(err error) bool {
+		return retry.IsRecoverable(err) && ctx.Err() == nil
+	})
+}
+
+// parseRPCAddrs parses a comma separated list of rpc addresses
+func parseRPCAddrs(value string) ([]string, error) {
+	var addrs []string
+	for _, addr := range strings.Split(value, ",") {
+		if addr = strings.TrimSpace(addr); addr == "" {
+			continue
+		}
+		if _, err := rpchttp.New(addr, "/websocket"); err != nil {
+			return nil, err
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs, nil
+}
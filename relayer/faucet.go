@@ -1,21 +1,20 @@
 package relayer
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"time"
 
-	ckeys "github.com/cosmos/cosmos-sdk/client/keys"
 	sdk "github.com/cosmos/cosmos-sdk/types"
-	"github.com/cosmos/cosmos-sdk/x/auth"
 	"github.com/cosmos/cosmos-sdk/x/bank"
 )
 
 // SendMsgWithKey allows the user to specify which relayer key will sign the message
-func (src *Chain) SendMsgWithKey(datagram sdk.Msg, keyName string) (res sdk.TxResponse, err error) {
+func (src *Chain) SendMsgWithKey(ctx context.Context, datagram sdk.Msg, keyName string) (res sdk.TxResponse, err error) {
 	var out []byte
-	if out, err = src.BuildAndSignTxWithKey([]sdk.Msg{datagram}, keyName); err != nil {
+	if out, err = src.BuildAndSignTxWithKey(ctx, []sdk.Msg{datagram}, keyName); err != nil {
 		return res, err
 	}
 	return src.BroadcastTxCommit(out)
@@ -23,7 +22,7 @@ func (src *Chain) SendMsgWithKey(datagram sdk.Msg, keyName string) (res sdk.TxRe
 }
 
 // BuildAndSignTxWithKey allows the user to specify which relayer key will sign the message
-func (src *Chain) BuildAndSignTxWithKey(datagram []sdk.Msg, keyName string) ([]byte, error) {
+func (src *Chain) BuildAndSignTxWithKey(ctx context.Context, datagram []sdk.Msg, keyName string) ([]byte, error) {
 
 	// Fetch account and sequence numbers for the account
 	info, err := src.Keybase.Key(keyName)
@@ -31,17 +30,7 @@ func (src *Chain) BuildAndSignTxWithKey(datagram []sdk.Msg, keyName string) ([]b
 		return nil, err
 	}
 
-	acc, err := auth.NewAccountRetriever(src.Cdc, src).GetAccount(info.GetAddress())
-	if err != nil {
-		return nil, err
-	}
-
-	defer src.UseSDKContext()()
-	return auth.NewTxBuilder(
-		auth.DefaultTxEncoder(src.Amino.Codec), acc.GetAccountNumber(),
-		acc.GetSequence(), src.Gas, src.GasAdjustment, false, src.ChainID,
-		src.Memo, sdk.NewCoins(), src.getGasPrices()).WithKeybase(src.Keybase).
-		BuildAndSign(info.GetName(), ckeys.DefaultKeyPass, datagram)
+	return src.buildAndSignTx(ctx, info.GetName(), info.GetAddress(), datagram)
 }
 
 // FaucetHandler listens for addresses
@@ -61,7 +50,7 @@ func (src *Chain) FaucetHandler(fromKey sdk.AccAddress, amount sdk.Coin) func(w
 			return
 		}
 
-		if err := src.faucetSend(fromKey, fr.addr(), amount); err != nil {
+		if err := src.faucetSend(r.Context(), fromKey, fr.addr(), amount); err != nil {
 			src.Error(err)
 			respondWithError(w, http.StatusInternalServerError, err.Error())
 			return
@@ -72,14 +61,14 @@ func (src *Chain) FaucetHandler(fromKey sdk.AccAddress, amount sdk.Coin) func(w
 	}
 }
 
-func (src *Chain) faucetSend(fromAddr, toAddr sdk.AccAddress, amount sdk.Coin) error {
+func (src *Chain) faucetSend(ctx context.Context, fromAddr, toAddr sdk.AccAddress, amount sdk.Coin) error {
 	// Set sdk config to use custom Bech32 account prefix
 
 	info, err := src.Keybase.KeyByAddress(fromAddr)
 	if err != nil {
 		return err
 	}
-	res, err := src.SendMsgWithKey(bank.NewMsgSend(fromAddr, toAddr, sdk.NewCoins(amount)), info.GetName())
+	res, err := src.SendMsgWithKey(ctx, bank.NewMsgSend(fromAddr, toAddr, sdk.NewCoins(amount)), info.GetName())
 	if err != nil || res.Code != 0 {
 		cs, err := GetCodespace(res.Codespace, int(res.Code))
 		if err != nil {
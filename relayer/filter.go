@@ -0,0 +1,194 @@
+package relayer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+var errPacketFiltered = errors.New("packet not allowed by the path filter")
+
+// PacketFilter defines which packets are relayed over a path. A packet is relayed
+// if it matches any of the Allow rules, or there are none, and none of the Deny rules
+type PacketFilter struct {
+	Allow []*PacketRule `yaml:"allow,omitempty" json:"allow,omitempty"`
+	Deny  []*PacketRule `yaml:"deny,omitempty" json:"deny,omitempty"`
+}
+
+// PacketRule matches packets on their decoded packet data, a packet matches
+// the rule if it matches every field that is set. Sender, Receiver, Denom and
+// MinAmount match ICS20 transfer packets, Fields matches any JSON packet data
+// by dot separated path, e.g. "value.sender"
+type PacketRule struct {
+	Sender    string            `yaml:"sender,omitempty" json:"sender,omitempty"`
+	Receiver  string            `yaml:"receiver,omitempty" json:"receiver,omitempty"`
+	Denom     string            `yaml:"denom,omitempty" json:"denom,omitempty"`
+	MinAmount string            `yaml:"min-amount,omitempty" json:"min-amount,omitempty"`
+	Fields    map[string]string `yaml:"fields,omitempty" json:"fields,omitempty"`
+}
+
+// Validate returns an error if any of the rules in the filter are invalid
+func (pf *PacketFilter) Validate() error {
+	if pf == nil {
+		return nil
+	}
+	for i, r := range pf.Allow {
+		if err := r.Validate(); err != nil {
+			return fmt.Errorf("invalid allow rule %d: %w", i, err)
+		}
+	}
+	for i, r := range pf.Deny {
+		if err := r.Validate(); err != nil {
+			return fmt.Errorf("invalid deny rule %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Allows returns true if the packet with the given data should be relayed,
+// a nil filter allows every packet
+func (pf *PacketFilter) Allows(packetData []byte) bool {
+	if pf == nil || (len(pf.Allow) == 0 && len(pf.Deny) == 0) {
+		return true
+	}
+
+	pd := decodePacketData(packetData)
+	for _, r := range pf.Deny {
+		if r.matches(pd) {
+			return false
+		}
+	}
+
+	if len(pf.Allow) == 0 {
+		return true
+	}
+	for _, r := range pf.Allow {
+		if r.matches(pd) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate returns an error if the rule is empty or its MinAmount isn't an integer
+func (r *PacketRule) Validate() error {
+	if r == nil || (r.Sender == "" && r.Receiver == "" && r.Denom == "" && r.MinAmount == "" && len(r.Fields) == 0) {
+		return fmt.Errorf("rule must set at least one field")
+	}
+	if r.MinAmount != "" {
+		if _, ok := sdk.NewIntFromString(r.MinAmount); !ok {
+			return fmt.Errorf("min-amount must be an integer, got %s", r.MinAmount)
+		}
+	}
+	return nil
+}
+
+// matches returns true if the packet data matches every field set in the rule
+func (r *PacketRule) matches(pd *packetData) bool {
+	if pd == nil {
+		return false
+	}
+	if r.Sender != "" && r.Sender != pd.sender {
+		return false
+	}
+	if r.Receiver != "" && r.Receiver != pd.receiver {
+		return false
+	}
+	if r.Denom != "" || r.MinAmount != "" {
+		if !r.matchesAmount(pd.amount) {
+			return false
+		}
+	}
+	for path, val := range r.Fields {
+		if field, ok := pd.field(path); !ok || field != val {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesAmount returns true if any of the coins is of Denom and at least MinAmount,
+// if either isn't set it matches any denom or amount
+func (r *PacketRule) matchesAmount(coins sdk.Coins) bool {
+	min, _ := sdk.NewIntFromString(r.MinAmount)
+	for _, c := range coins {
+		if r.Denom != "" && c.Denom != r.Denom {
+			continue
+		}
+		if r.MinAmount != "" && c.Amount.LT(min) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// packetData holds the decoded packet data that rules match against
+type packetData struct {
+	fields   map[string]interface{}
+	sender   string
+	receiver string
+	amount   sdk.Coins
+}
+
+// decodePacketData decodes JSON packet data, returning nil if the data isn't JSON.
+// Amino JSON is unwrapped so ICS20 transfer data can be matched on directly.
+func decodePacketData(bz []byte) *packetData {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(bz, &fields); err != nil {
+		return nil
+	}
+
+	pd := &packetData{fields: fields}
+
+	// amino wraps registered types as {"type": "...", "value": {...}}
+	ftpd := fields
+	if val, ok := fields["value"].(map[string]interface{}); ok {
+		ftpd = val
+	}
+
+	pd.sender, _ = ftpd["sender"].(string)
+	pd.receiver, _ = ftpd["receiver"].(string)
+	if coins, ok := ftpd["amount"].([]interface{}); ok {
+		for _, c := range coins {
+			coin, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			denom, _ := coin["denom"].(string)
+			amount, _ := coin["amount"].(string)
+			amt, ok := sdk.NewIntFromString(amount)
+			if denom == "" || !ok {
+				continue
+			}
+			pd.amount = append(pd.amount, sdk.Coin{Denom: denom, Amount: amt})
+		}
+	}
+	return pd
+}
+
+// field returns the string value of the field at the dot separated path
+func (pd *packetData) field(path string) (string, bool) {
+	var cur interface{} = pd.fields
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := cur.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		if cur, ok = obj[key]; !ok {
+			return "", false
+		}
+	}
+
+	switch v := cur.(type) {
+	case string:
+		return v, true
+	case map[string]interface{}, []interface{}:
+		return "", false
+	default:
+		return fmt.Sprint(v), true
+	}
+}
@@ -0,0 +1,84 @@
+package relayer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testTransferData = `{"type":"ibc/transfer/PacketDataTransfer","value":{"amount":[{"denom":"transfer/ibczeroxfer/n0token","amount":"100"}],"sender":"cosmos1sender","receiver":"cosmos1receiver","source":true}}`
+	testRawData      = `{"sender":"cosmos1sender","memo":{"id":7}}`
+)
+
+func TestPacketFilterAllows(t *testing.T) {
+	cases := []struct {
+		name   string
+		filter *PacketFilter
+		data   string
+		allow  bool
+	}{
+		{"nil filter", nil, testTransferData, true},
+		{"empty filter", &PacketFilter{}, "not json", true},
+		{"allow sender", &PacketFilter{Allow: []*PacketRule{{Sender: "cosmos1sender"}}}, testTransferData, true},
+		{"allow other sender", &PacketFilter{Allow: []*PacketRule{{Sender: "cosmos1other"}}}, testTransferData, false},
+		{"allow any rule", &PacketFilter{Allow: []*PacketRule{{Sender: "cosmos1other"}, {Receiver: "cosmos1receiver"}}}, testTransferData, true},
+		{"allow every field", &PacketFilter{Allow: []*PacketRule{{Sender: "cosmos1sender", Receiver: "cosmos1other"}}}, testTransferData, false},
+		{"deny sender", &PacketFilter{Deny: []*PacketRule{{Sender: "cosmos1sender"}}}, testTransferData, false},
+		{"deny other sender", &PacketFilter{Deny: []*PacketRule{{Sender: "cosmos1other"}}}, testTransferData, true},
+		{"deny takes precedence", &PacketFilter{
+			Allow: []*PacketRule{{Sender: "cosmos1sender"}},
+			Deny:  []*PacketRule{{Receiver: "cosmos1receiver"}},
+		}, testTransferData, false},
+		{"allow when deny doesn't match", &PacketFilter{
+			Allow: []*PacketRule{{Sender: "cosmos1sender"}},
+			Deny:  []*PacketRule{{Receiver: "cosmos1other"}},
+		}, testTransferData, true},
+		{"allow rules don't match data that isn't json", &PacketFilter{Allow: []*PacketRule{{Sender: "cosmos1sender"}}}, "not json", false},
+		{"deny rules don't match data that isn't json", &PacketFilter{Deny: []*PacketRule{{Sender: "cosmos1sender"}}}, "not json", true},
+		{"denom", &PacketFilter{Allow: []*PacketRule{{Denom: "transfer/ibczeroxfer/n0token"}}}, testTransferData, true},
+		{"other denom", &PacketFilter{Allow: []*PacketRule{{Denom: "stake"}}}, testTransferData, false},
+		{"min amount equal", &PacketFilter{Allow: []*PacketRule{{MinAmount: "100"}}}, testTransferData, true},
+		{"min amount above", &PacketFilter{Allow: []*PacketRule{{MinAmount: "101"}}}, testTransferData, false},
+		{"min amount of denom", &PacketFilter{Allow: []*PacketRule{{Denom: "transfer/ibczeroxfer/n0token", MinAmount: "50"}}}, testTransferData, true},
+		{"min amount of other denom", &PacketFilter{Allow: []*PacketRule{{Denom: "stake", MinAmount: "50"}}}, testTransferData, false},
+		{"field", &PacketFilter{Allow: []*PacketRule{{Fields: map[string]string{"value.source": "true"}}}}, testTransferData, true},
+		{"nested field", &PacketFilter{Allow: []*PacketRule{{Fields: map[string]string{"memo.id": "7"}}}}, testRawData, true},
+		{"missing field", &PacketFilter{Allow: []*PacketRule{{Fields: map[string]string{"memo.missing": "7"}}}}, testRawData, false},
+		{"object field", &PacketFilter{Allow: []*PacketRule{{Fields: map[string]string{"memo": ""}}}}, testRawData, false},
+		{"raw sender", &PacketFilter{Allow: []*PacketRule{{Sender: "cosmos1sender"}}}, testRawData, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.allow, tc.filter.Allows([]byte(tc.data)))
+		})
+	}
+}
+
+func TestPacketRuleValidate(t *testing.T) {
+	cases := []struct {
+		name  string
+		rule  *PacketRule
+		valid bool
+	}{
+		{"nil", nil, false},
+		{"empty", &PacketRule{}, false},
+		{"sender", &PacketRule{Sender: "cosmos1sender"}, true},
+		{"fields", &PacketRule{Fields: map[string]string{"a": "b"}}, true},
+		{"min amount", &PacketRule{MinAmount: "1000000000000000000000"}, true},
+		{"decimal min amount", &PacketRule{MinAmount: "1.5"}, false},
+		{"min amount with denom", &PacketRule{MinAmount: "10stake"}, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.rule.Validate()
+			if tc.valid {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
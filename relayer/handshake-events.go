@@ -0,0 +1,167 @@
+package relayer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	connState "github.com/cosmos/cosmos-sdk/x/ibc/03-connection/exported"
+	connTypes "github.com/cosmos/cosmos-sdk/x/ibc/03-connection/types"
+	chanState "github.com/cosmos/cosmos-sdk/x/ibc/04-channel/exported"
+	chanTypes "github.com/cosmos/cosmos-sdk/x/ibc/04-channel/types"
+)
+
+var (
+	// connHandshakeEvents are the events emitted by each step of the connection handshake
+	connHandshakeEvents = []string{
+		connTypes.EventTypeConnectionOpenInit,
+		connTypes.EventTypeConnectionOpenTry,
+		connTypes.EventTypeConnectionOpenAck,
+		connTypes.EventTypeConnectionOpenConfirm,
+	}
+
+	// chanHandshakeEvents are the events emitted by each step of the channel opening and closing handshakes
+	chanHandshakeEvents = []string{
+		chanTypes.EventTypeChannelOpenInit,
+		chanTypes.EventTypeChannelOpenTry,
+		chanTypes.EventTypeChannelOpenAck,
+		chanTypes.EventTypeChannelOpenConfirm,
+		chanTypes.EventTypeChannelCloseInit,
+		chanTypes.EventTypeChannelCloseConfirm,
+	}
+)
+
+// handshakeRelayer completes the connection and channel handshakes on a path that were
+// started by someone else, such as a counterparty closing the channel. It never starts a
+// handshake itself, it only sends the next step once one end has moved.
+type handshakeRelayer struct {
+	src, dst *Chain
+	ordering chanState.Order
+
+	// mu serializes the steps, as the events from the tx of one step trigger the next
+	mu sync.Mutex
+
+	// lastErr is the last error logged, so that a stuck handshake is only reported once
+	lastErr string
+}
+
+func newHandshakeRelayer(src, dst *Chain, ordered bool) *handshakeRelayer {
+	ordering := chanState.UNORDERED
+	if ordered {
+		ordering = chanState.ORDERED
+	}
+	return &handshakeRelayer{src: src, dst: dst, ordering: ordering}
+}
+
+// handleEvents sends the next handshake step if the tx events from c include
+// a connection or channel handshake step on c's end of the path
+func (h *handshakeRelayer) handleEvents(ctx context.Context, c *Chain, events map[string][]string) {
+	if !hasHandshakeEvent(c.PathEnd, events) {
+		return
+	}
+	h.converge(ctx)
+}
+
+// converge sends the next handshake step if the ends of the path are in different states,
+// errors are logged as the step is retried on the next event or reconcile
+func (h *handshakeRelayer) converge(ctx context.Context) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.step(ctx); err != nil {
+		if err.Error() != h.lastErr {
+			h.src.Error(fmt.Errorf("failed to relay handshake: %w", err))
+		}
+		h.lastErr = err.Error()
+		return
+	}
+	h.lastErr = ""
+}
+
+// step sends the next step of the connection handshake, or of the channel handshake
+// once the connection is open. Closing the channel takes precedence over opening it,
+// so a channel closed on one end is closed on the other one.
+func (h *handshakeRelayer) step(ctx context.Context) error {
+	src, dst := h.src, h.dst
+
+	conn, err := QueryConnectionPair(ctx, src, dst, 0, 0)
+	if err != nil {
+		return err
+	}
+	srcConn, dstConn := conn[src.ChainID].Connection.Connection.State, conn[dst.ChainID].Connection.Connection.State
+	switch {
+	case srcConn == connState.UNINITIALIZED && dstConn == connState.UNINITIALIZED:
+		return nil
+	case srcConn != connState.OPEN || dstConn != connState.OPEN:
+		msgs, err := src.CreateConnectionStep(ctx, dst)
+		if err != nil {
+			return err
+		}
+		if h.send(ctx, msgs) {
+			src.Log(fmt.Sprintf("★ Connection created: [%s]client{%s}conn{%s} -> [%s]client{%s}conn{%s}",
+				src.ChainID, src.PathEnd.ClientID, src.PathEnd.ConnectionID,
+				dst.ChainID, dst.PathEnd.ClientID, dst.PathEnd.ConnectionID))
+		}
+		return nil
+	}
+
+	chans, err := QueryChannelPair(ctx, src, dst, 0, 0)
+	if err != nil {
+		return err
+	}
+	srcChan, dstChan := chans[src.ChainID].Channel.Channel.State, chans[dst.ChainID].Channel.Channel.State
+	switch {
+	case srcChan == dstChan && (srcChan == chanState.UNINITIALIZED || srcChan == chanState.OPEN || srcChan == chanState.CLOSED):
+		return nil
+	case srcChan == chanState.CLOSED || dstChan == chanState.CLOSED:
+		msgs, err := src.CloseChannelStep(ctx, dst)
+		if err != nil {
+			return err
+		}
+		if h.send(ctx, msgs) {
+			src.Log(fmt.Sprintf("★ Closed channel between [%s]chan{%s}port{%s} -> [%s]chan{%s}port{%s}",
+				src.ChainID, src.PathEnd.ChannelID, src.PathEnd.PortID,
+				dst.ChainID, dst.PathEnd.ChannelID, dst.PathEnd.PortID))
+		}
+	default:
+		msgs, err := src.CreateChannelStep(ctx, dst, h.ordering)
+		if err != nil {
+			return err
+		}
+		if h.send(ctx, msgs) {
+			src.Log(fmt.Sprintf("★ Channel created: [%s]chan{%s}port{%s} -> [%s]chan{%s}port{%s}",
+				src.ChainID, src.PathEnd.ChannelID, src.PathEnd.PortID,
+				dst.ChainID, dst.PathEnd.ChannelID, dst.PathEnd.PortID))
+		}
+	}
+	return nil
+}
+
+// send sends the msgs of a handshake step, it returns true if they completed the handshake
+func (h *handshakeRelayer) send(ctx context.Context, msgs *RelayMsgs) bool {
+	if !msgs.Ready() {
+		return false
+	}
+	msgs.Send(ctx, h.src, h.dst)
+	return msgs.success && msgs.last
+}
+
+// hasHandshakeEvent returns true if the events include a handshake step on the connection or channel of pe
+func hasHandshakeEvent(pe *PathEnd, events map[string][]string) bool {
+	for _, eventType := range connHandshakeEvents {
+		for _, connID := range events[eventType+"."+connTypes.AttributeKeyConnectionID] {
+			if connID == pe.ConnectionID {
+				return true
+			}
+		}
+	}
+	for _, eventType := range chanHandshakeEvents {
+		ports := events[eventType+"."+chanTypes.AttributeKeyPortID]
+		for i, chanID := range events[eventType+"."+chanTypes.AttributeKeyChannelID] {
+			if chanID == pe.ChannelID && i < len(ports) && ports[i] == pe.PortID {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -1,6 +1,7 @@
 package relayer
 
 import (
+	"context"
 	"sync"
 
 	tmclient "github.com/cosmos/cosmos-sdk/x/ibc/07-tendermint/types"
@@ -8,8 +9,8 @@ import (
 
 // NewSyncHeaders returns a new instance of map[string]*tmclient.Header that can be easily
 // kept "reasonably up to date"
-func NewSyncHeaders(chains ...*Chain) (*SyncHeaders, error) {
-	mp, err := UpdatesWithHeaders(chains...)
+func NewSyncHeaders(ctx context.Context, chains ...*Chain) (*SyncHeaders, error) {
+	mp, err := UpdatesWithHeaders(ctx, chains...)
 	if err != nil {
 		return nil, err
 	}
@@ -25,8 +26,8 @@ type SyncHeaders struct {
 }
 
 // Update the header for a given chain
-func (uh *SyncHeaders) Update(c *Chain) error {
-	hd, err := c.UpdateLiteWithHeader()
+func (uh *SyncHeaders) Update(ctx context.Context, c *Chain) error {
+	hd, err := c.UpdateLiteWithHeader(ctx)
 	if err != nil {
 		return err
 	}
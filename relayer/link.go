@@ -0,0 +1,364 @@
+package relayer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	retry "github.com/avast/retry-go"
+	chanState "github.com/cosmos/cosmos-sdk/x/ibc/04-channel/exported"
+)
+
+const (
+	linkStageClients    = "clients"
+	linkStageConnection = "connection"
+	linkStageChannel    = "channel"
+)
+
+var (
+	// defaultLinkAttempts is how many times a handshake step is tried before giving up
+	defaultLinkAttempts = uint(3)
+
+	// defaultLinkBackoff is the delay before retrying a failed step when no timeout is given
+	defaultLinkBackoff = time.Second * 5
+
+	linkStages = []string{linkStageClients, linkStageConnection, linkStageChannel}
+)
+
+// LinkStep is a tx sent to a chain while linking a path
+type LinkStep struct {
+	Stage   string    `yaml:"stage" json:"stage"`
+	Msg     string    `yaml:"msg" json:"msg"`
+	ChainID string    `yaml:"chain-id" json:"chain-id"`
+	TxHash  string    `yaml:"tx-hash" json:"tx-hash"`
+	Time    time.Time `yaml:"time" json:"time"`
+}
+
+// LinkProgress records how far linking a path got, so that linking a path that was
+// partially linked resumes where it stopped. It only applies to the identifiers it
+// was recorded for and is started over if the path's identifiers change.
+type LinkProgress struct {
+	Identifiers string     `yaml:"identifiers" json:"identifiers"`
+	Completed   []string   `yaml:"completed,omitempty" json:"completed,omitempty"`
+	Steps       []LinkStep `yaml:"steps,omitempty" json:"steps,omitempty"`
+}
+
+// done returns true if the stage has been completed
+func (lp *LinkProgress) done(stage string) bool {
+	if lp == nil {
+		return false
+	}
+	for _, s := range lp.Completed {
+		if s == stage {
+			return true
+		}
+	}
+	return false
+}
+
+// Summary returns the stages of linking the path and the txs sent for each of them
+func (lp *LinkProgress) Summary() string {
+	var sb strings.Builder
+	for _, stage := range linkStages {
+		status := "incomplete"
+		if lp.done(stage) {
+			status = "complete"
+		}
+		fmt.Fprintf(&sb, "%s: %s\n", stage, status)
+
+		var sent int
+		for _, s := range lp.Steps {
+			if s.Stage != stage {
+				continue
+			}
+			fmt.Fprintf(&sb, "  - [%s] %s tx(%s) at %s\n", s.ChainID, s.Msg, s.TxHash, s.Time.Format(time.RFC3339))
+			sent++
+		}
+		if sent == 0 && lp.done(stage) {
+			sb.WriteString("  - already existed, no txs sent\n")
+		}
+	}
+	return sb.String()
+}
+
+// linkIdentifiers returns the identifiers of the path ends that link progress is recorded for
+func linkIdentifiers(src, dst *Chain) string {
+	return fmt.Sprintf("[%s]client{%s}conn{%s}chan{%s}port{%s} -> [%s]client{%s}conn{%s}chan{%s}port{%s}",
+		src.ChainID, src.PathEnd.ClientID, src.PathEnd.ConnectionID, src.PathEnd.ChannelID, src.PathEnd.PortID,
+		dst.ChainID, dst.PathEnd.ClientID, dst.PathEnd.ConnectionID, dst.PathEnd.ChannelID, dst.PathEnd.PortID)
+}
+
+// Linker creates the clients, connection and channel for a path. Each step of the
+// handshakes is retried with backoff, and the completed steps are recorded in Progress.
+type Linker struct {
+	// Timeout is how long to wait between the steps of a handshake
+	Timeout time.Duration
+
+	// MaxAttempts is how many times a step is tried before linking fails
+	MaxAttempts uint
+
+	// Backoff is the delay before a failed step is retried, it doubles after each attempt
+	Backoff time.Duration
+
+	// Progress records the completed steps, no progress is recorded if it is nil
+	Progress *LinkProgress
+
+	// OnProgress is called after each change to Progress, e.g. to save it
+	OnProgress func() error
+}
+
+// NewLinker returns a Linker that waits for to between handshake steps and retries
+// failed steps defaultLinkAttempts times, starting with a backoff of to
+func NewLinker(to time.Duration) *Linker {
+	return &Linker{Timeout: to, MaxAttempts: defaultLinkAttempts, Backoff: to}
+}
+
+// Link creates the clients, connection and channel between src and dst. The
+// stages that Progress shows are complete for the path's identifiers are skipped.
+func (l *Linker) Link(ctx context.Context, src, dst *Chain, ordered bool) error {
+	if ids := linkIdentifiers(src, dst); l.Progress != nil && l.Progress.Identifiers != ids {
+		if l.Progress.Identifiers != "" {
+			src.Log("- the path's identifiers have changed since it was last linked, starting over")
+		}
+		*l.Progress = LinkProgress{Identifiers: ids}
+	}
+
+	if err := l.createClients(ctx, src, dst); err != nil {
+		return err
+	}
+	if err := l.createConnection(ctx, src, dst); err != nil {
+		return err
+	}
+	return l.createChannel(ctx, src, dst, ordered)
+}
+
+// createClients creates the clients for src on dst and dst on src if they don't exist
+func (l *Linker) createClients(ctx context.Context, src, dst *Chain) error {
+	if l.skip(src, linkStageClients) {
+		return nil
+	}
+
+	sent, err := l.handshake(ctx, src, dst, linkStageClients, func() (*RelayMsgs, error) {
+		return src.CreateClientsStep(ctx, dst)
+	})
+	if err != nil {
+		return fmt.Errorf("! Clients failed: [%s]client(%s) and [%s]client(%s): %w",
+			src.ChainID, src.PathEnd.ClientID, dst.ChainID, dst.PathEnd.ClientID, err)
+	}
+
+	switch {
+	case src.dryRun:
+		return nil
+	case sent:
+		src.Log(fmt.Sprintf("★ Clients created: [%s]client(%s) and [%s]client(%s)",
+			src.ChainID, src.PathEnd.ClientID, dst.ChainID, dst.PathEnd.ClientID))
+	default:
+		src.Log(fmt.Sprintf("- clients [%s]client(%s) and [%s]client(%s) already exist",
+			src.ChainID, src.PathEnd.ClientID, dst.ChainID, dst.PathEnd.ClientID))
+	}
+	l.complete(src, linkStageClients)
+	return nil
+}
+
+// createConnection runs the connection handshake until the connection is open
+func (l *Linker) createConnection(ctx context.Context, src, dst *Chain) error {
+	if l.skip(src, linkStageConnection) {
+		return nil
+	}
+
+	sent, err := l.handshake(ctx, src, dst, linkStageConnection, func() (*RelayMsgs, error) {
+		return src.CreateConnectionStep(ctx, dst)
+	})
+	if err != nil {
+		return fmt.Errorf("! Connection failed: [%s]client{%s}conn{%s} -> [%s]client{%s}conn{%s}: %w",
+			src.ChainID, src.PathEnd.ClientID, src.PathEnd.ConnectionID,
+			dst.ChainID, dst.PathEnd.ClientID, dst.PathEnd.ConnectionID, err)
+	}
+
+	switch {
+	case src.dryRun:
+		return nil
+	case sent:
+		if src.debug {
+			conns, err := QueryConnectionPair(ctx, src, dst, 0, 0)
+			if err != nil {
+				return err
+			}
+			logConnectionStates(src, dst, conns)
+		}
+		src.Log(fmt.Sprintf("★ Connection created: [%s]client{%s}conn{%s} -> [%s]client{%s}conn{%s}",
+			src.ChainID, src.PathEnd.ClientID, src.PathEnd.ConnectionID,
+			dst.ChainID, dst.PathEnd.ClientID, dst.PathEnd.ConnectionID))
+	default:
+		src.Log(fmt.Sprintf("- connection [%s]conn{%s} -> [%s]conn{%s} is already open",
+			src.ChainID, src.PathEnd.ConnectionID, dst.ChainID, dst.PathEnd.ConnectionID))
+	}
+	l.complete(src, linkStageConnection)
+	return nil
+}
+
+// createChannel runs the channel handshake until the channel is open
+func (l *Linker) createChannel(ctx context.Context, src, dst *Chain, ordered bool) error {
+	if l.skip(src, linkStageChannel) {
+		return nil
+	}
+
+	order := chanState.UNORDERED
+	if ordered {
+		order = chanState.ORDERED
+	}
+
+	sent, err := l.handshake(ctx, src, dst, linkStageChannel, func() (*RelayMsgs, error) {
+		return src.CreateChannelStep(ctx, dst, order)
+	})
+	if err != nil {
+		return fmt.Errorf("! Channel failed: [%s]chan{%s}port{%s} -> [%s]chan{%s}port{%s}: %w",
+			src.ChainID, src.PathEnd.ChannelID, src.PathEnd.PortID,
+			dst.ChainID, dst.PathEnd.ChannelID, dst.PathEnd.PortID, err)
+	}
+
+	switch {
+	case src.dryRun:
+		return nil
+	case sent:
+		if src.debug {
+			chans, err := QueryChannelPair(ctx, src, dst, 0, 0)
+			if err != nil {
+				return err
+			}
+			logChannelStates(src, dst, chans)
+		}
+		src.Log(fmt.Sprintf("★ Channel created: [%s]chan{%s}port{%s} -> [%s]chan{%s}port{%s}",
+			src.ChainID, src.PathEnd.ChannelID, src.PathEnd.PortID,
+			dst.ChainID, dst.PathEnd.ChannelID, dst.PathEnd.PortID))
+	default:
+		src.Log(fmt.Sprintf("- channel [%s]chan{%s}port{%s} -> [%s]chan{%s}port{%s} is already open",
+			src.ChainID, src.PathEnd.ChannelID, src.PathEnd.PortID,
+			dst.ChainID, dst.PathEnd.ChannelID, dst.PathEnd.PortID))
+	}
+	l.complete(src, linkStageChannel)
+	return nil
+}
+
+// handshake sends the msgs returned by step until there are none left or the last step
+// has been sent, retrying failed steps. It returns true if any msgs were sent.
+func (l *Linker) handshake(ctx context.Context, src, dst *Chain, stage string, step func() (*RelayMsgs, error)) (sent bool, err error) {
+	for {
+		var msgs *RelayMsgs
+		err = l.retry(ctx, src, stage, func() (err error) {
+			if msgs, err = step(); err != nil {
+				// retrying won't change the states the handshake is in or the chain config
+				if errors.Is(err, errHandshakeBlocked) || errors.Is(err, errHandshakeStuck) || errors.Is(err, errTrustingPeriod) {
+					return retry.Unrecoverable(err)
+				}
+				return err
+			}
+			if !msgs.Ready() {
+				return nil
+			}
+			// the txs that went through are recorded even if the other chain's failed,
+			// the failed txs have already been logged by Send
+			msgs.Send(ctx, src, dst)
+			l.record(src, dst, stage, msgs)
+			if !msgs.success {
+				return fmt.Errorf("%s step failed", stage)
+			}
+			return nil
+		})
+
+		switch {
+		// in a dry run the handshake can't go further than the step it is waiting on
+		case src.dryRun && errors.Is(err, errHandshakeBlocked):
+			src.Log(fmt.Sprintf("- dry run stopped, %s", err))
+			return sent, nil
+		case err != nil:
+			return sent, err
+		case !msgs.Ready():
+			return sent, nil
+		}
+
+		sent = true
+		// in a dry run nothing is committed, so the next step can't be built
+		if src.dryRun {
+			return sent, nil
+		}
+
+		if msgs.last {
+			return sent, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return sent, ctx.Err()
+		case <-time.After(l.Timeout):
+		}
+	}
+}
+
+// retry calls f until it succeeds, MaxAttempts is reached or ctx is done
+func (l *Linker) retry(ctx context.Context, c *Chain, stage string, f func() error) error {
+	attempts := l.MaxAttempts
+	if attempts == 0 {
+		attempts = defaultLinkAttempts
+	}
+	return retry.Do(f,
+		retry.Attempts(attempts),
+		retry.Delay(l.Backoff),
+		retry.DelayType(retry.BackOffDelay),
+		retry.LastErrorOnly(true),
+		retryUntilDone(ctx),
+		retry.OnRetry(func(n uint, err error) {
+			if n+1 < attempts {
+				c.Log(fmt.Sprintf("- %s attempt %d/%d failed, retrying in %s: %s", stage, n+1, attempts, l.Backoff*(1<<n), err))
+			}
+		}),
+	)
+}
+
+// skip returns true if the stage is already complete
+func (l *Linker) skip(c *Chain, stage string) bool {
+	if !l.Progress.done(stage) {
+		return false
+	}
+	c.Log(fmt.Sprintf("- %s already completed for the path, skipping", stage))
+	return true
+}
+
+// record adds the txs the msgs were sent in to Progress
+func (l *Linker) record(src, dst *Chain, stage string, msgs *RelayMsgs) {
+	if l.Progress == nil || src.dryRun || (msgs.srcHash == "" && msgs.dstHash == "") {
+		return
+	}
+
+	// the handshake msg comes after any client updates
+	now := time.Now()
+	if msgs.srcHash != "" {
+		l.Progress.Steps = append(l.Progress.Steps, LinkStep{stage, msgs.Src[len(msgs.Src)-1].Type(), src.ChainID, msgs.srcHash, now})
+	}
+	if msgs.dstHash != "" {
+		l.Progress.Steps = append(l.Progress.Steps, LinkStep{stage, msgs.Dst[len(msgs.Dst)-1].Type(), dst.ChainID, msgs.dstHash, now})
+	}
+	l.save(src)
+}
+
+// complete marks the stage as complete in Progress
+func (l *Linker) complete(c *Chain, stage string) {
+	if l.Progress == nil || c.dryRun {
+		return
+	}
+	l.Progress.Completed = append(l.Progress.Completed, stage)
+	l.save(c)
+}
+
+// save calls OnProgress, failing to save the progress only means
+// that linking the path can't resume from it, so it isn't fatal
+func (l *Linker) save(c *Chain) {
+	if l.OnProgress == nil {
+		return
+	}
+	if err := l.OnProgress(); err != nil {
+		c.Error(fmt.Errorf("failed to save link progress: %w", err))
+	}
+}
@@ -0,0 +1,161 @@
+package relayer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+)
+
+var (
+	// listenStallTimeout is how long a subscription can go without a new block
+	// before the connection is considered lost and is rebuilt
+	listenStallTimeout = time.Minute
+
+	// listenReconnectDelay and listenReconnectMaxDelay bound the backoff
+	// between attempts to rebuild a lost connection
+	listenReconnectDelay    = time.Second
+	listenReconnectMaxDelay = time.Second * 30
+
+	// subscribeTimeout is how long to wait for a subscribe or unsubscribe call
+	subscribeTimeout = time.Second * 5
+)
+
+// eventSubscription holds the tx and block event subscriptions for a chain. Each
+// subscription uses its own websocket connection so that it can be rebuilt without
+// interrupting queries, or the subscriptions of other paths using the same chain.
+type eventSubscription struct {
+	chain  *Chain
+	addr   string
+	client rpcclient.Client
+
+	txs, blocks <-chan ctypes.ResultEvent
+	lastBlock   time.Time
+
+	// unsubscribe funcs for the tx and block subscriptions
+	unsubscribes []func()
+}
+
+// subscribeEvents opens a new connection to the first healthy rpc endpoint for the
+// chain and subscribes to its tx and block events
+func (src *Chain) subscribeEvents(ctx context.Context) (sub *eventSubscription, err error) {
+	err = src.rpc.do(func(e *rpcEndpoint) error {
+		client, err := newRPCClient(e.addr, src.timeout)
+		if err != nil {
+			return err
+		}
+		if err = client.Start(); err != nil {
+			return err
+		}
+
+		sub = &eventSubscription{chain: src, addr: e.addr, client: client, lastBlock: time.Now()}
+		var unsubscribe func()
+		if sub.txs, unsubscribe, err = subscribe(ctx, client, src.ChainID, txEvents); err != nil {
+			sub.close()
+			return err
+		}
+		sub.unsubscribes = append(sub.unsubscribes, unsubscribe)
+		if sub.blocks, unsubscribe, err = subscribe(ctx, client, src.ChainID, blEvents); err != nil {
+			sub.close()
+			return err
+		}
+		sub.unsubscribes = append(sub.unsubscribes, unsubscribe)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// close unsubscribes from the events and closes the connection, errors are
+// ignored as the connection is likely already broken when this is called
+func (sub *eventSubscription) close() {
+	for _, unsubscribe := range sub.unsubscribes {
+		unsubscribe()
+	}
+	sub.unsubscribes = nil
+	_ = sub.client.Stop()
+}
+
+// stalled returns true if no block has been received within listenStallTimeout
+func (sub *eventSubscription) stalled() bool {
+	return time.Since(sub.lastBlock) > listenStallTimeout
+}
+
+// resubscribe closes the subscription and rebuilds it, retrying with backoff until it
+// succeeds. The endpoint the subscription was using is marked as unhealthy, so the next
+// healthy endpoint is used if there is one. It returns false if ctx is done before the
+// subscription is rebuilt.
+func (sub *eventSubscription) resubscribe(ctx context.Context) (*eventSubscription, bool) {
+	c := sub.chain
+	sub.close()
+	c.rpc.failedAddr(sub.addr, fmt.Errorf("lost event subscription"))
+
+	delay := listenReconnectDelay
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, false
+		case <-time.After(delay):
+		}
+
+		newSub, err := c.subscribeEvents(ctx)
+		if err == nil {
+			c.Log(fmt.Sprintf("- reconnected to %s, listening to tx and block events...", c.ChainID))
+			return newSub, true
+		}
+
+		if delay *= 2; delay > listenReconnectMaxDelay {
+			delay = listenReconnectMaxDelay
+		}
+		c.Error(fmt.Errorf("failed to reconnect, retrying in %s: %w", delay, err))
+	}
+}
+
+// subscribe subscribes the client to the query, the returned func unsubscribes from it
+func subscribe(ctx context.Context, client rpcclient.Client, chainID, query string) (<-chan ctypes.ResultEvent, func(), error) {
+	suffix, err := GenerateRandomString(8)
+	if err != nil {
+		return nil, nil, err
+	}
+	subscriber := fmt.Sprintf("%s-subscriber-%s", chainID, suffix)
+
+	// NOTE: the context only bounds the subscribe call, the subscription
+	// lasts until it is unsubscribed or the client is stopped
+	subCtx, cancel := context.WithTimeout(ctx, subscribeTimeout)
+	defer cancel()
+
+	eventChan, err := client.Subscribe(subCtx, subscriber, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	unsubscribe := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), subscribeTimeout)
+		defer cancel()
+		_ = client.Unsubscribe(ctx, subscriber, query)
+	}
+	return eventChan, unsubscribe, nil
+}
+
+// reconcile relays any packets and acknowledgements that were missed while the
+// relayer wasn't listening, errors are logged as they are picked up on the next run
+func reconcile(ctx context.Context, src, dst *Chain, sh *SyncHeaders, strategy Strategy, ordered bool) {
+	if err := sh.Update(ctx, src); err != nil {
+		src.Error(err)
+		return
+	}
+	if err := sh.Update(ctx, dst); err != nil {
+		dst.Error(err)
+		return
+	}
+	if err := RelayUnrelayedPackets(ctx, src, dst, sh, strategy, ordered); err != nil {
+		src.Error(err)
+	}
+	if err := RelayUnrelayedAcks(ctx, src, dst, sh, strategy); err != nil {
+		src.Error(err)
+	}
+}
@@ -1,9 +1,11 @@
 package relayer
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	connTypes "github.com/cosmos/cosmos-sdk/x/ibc/03-connection/types"
@@ -39,7 +41,22 @@ func (c *Chain) LogSuccessTx(res sdk.TxResponse, msgs []sdk.Msg) {
 	c.logger.Info(fmt.Sprintf("✔ [%s]@{%d} - msg(%s) hash(%s)", c.ChainID, res.Height, getMsgAction(msgs), res.TxHash))
 }
 
+// logDryRun logs the msgs that would be sent to c in a tx, and the gas the tx is estimated to use
+func (c *Chain) logDryRun(ctx context.Context, msgs []sdk.Msg) {
+	gas, err := c.EstimateGas(ctx, msgs)
+	if err != nil {
+		c.Log(fmt.Sprintf("? [%s] - dry run msg(%s) gas(unknown: %s)", c.ChainID, getMsgAction(msgs), err))
+	} else {
+		c.Log(fmt.Sprintf("? [%s] - dry run msg(%s) gas(%d)", c.ChainID, getMsgAction(msgs), gas))
+	}
+	c.Print(msgs, false, true)
+}
+
 func (c *Chain) logPacketsRelayed(dst *Chain, num int) {
+	// nothing is relayed in a dry run
+	if c.dryRun || dst.dryRun {
+		return
+	}
 	dst.Log(fmt.Sprintf("★ Relayed %d packets: [%s]port{%s}->[%s]port{%s}", num, dst.ChainID, dst.PathEnd.PortID, c.ChainID, c.PathEnd.PortID))
 }
 
@@ -70,8 +87,9 @@ func logConnectionStates(src, dst *Chain, conn map[string]connTypes.ConnectionRe
 	))
 }
 
-func (c *Chain) logCreateClient(dst *Chain, dstH uint64) {
-	c.Log(fmt.Sprintf("- [%s] -> creating client for [%s]header-height{%d} trust-period(%s)", c.ChainID, dst.ChainID, dstH, dst.GetTrustingPeriod()))
+func (c *Chain) logCreateClient(dst *Chain, dstH uint64, unbonding time.Duration) {
+	c.Log(fmt.Sprintf("- [%s] -> creating client for [%s]header-height{%d} trust-period(%s) unbonding-period(%s) max-clock-drift(%s)",
+		c.ChainID, dst.ChainID, dstH, dst.GetTrustingPeriod(), unbonding, c.GetMaxClockDrift()))
 }
 
 func (c *Chain) logTx(events map[string][]string) {
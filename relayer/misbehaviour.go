@@ -0,0 +1,160 @@
+package relayer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	tmclient "github.com/cosmos/cosmos-sdk/x/ibc/07-tendermint/types"
+	lite "github.com/tendermint/tendermint/lite2"
+	litep "github.com/tendermint/tendermint/lite2/provider"
+	litehttp "github.com/tendermint/tendermint/lite2/provider/http"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+// ErrConflictingHeaders is returned when a witness for a chain has a validly signed header
+// that conflicts with the one from the chain's rpc-addr, which means the chain has forked
+type ErrConflictingHeaders struct {
+	ChainID string
+	Witness string
+
+	Primary     *tmclient.Header
+	Conflicting *tmclient.Header
+}
+
+func (e *ErrConflictingHeaders) Error() string {
+	return fmt.Sprintf("header %X at height %d from %s conflicts with header %X from witness %s",
+		e.Primary.Hash(), e.Primary.Height, e.ChainID, e.Conflicting.Hash(), e.Witness)
+}
+
+// IsConflictingHeaders returns the ErrConflictingHeaders in err's chain, if there is one
+func IsConflictingHeaders(err error) (*ErrConflictingHeaders, bool) {
+	var conflict *ErrConflictingHeaders
+	if errors.As(err, &conflict) {
+		return conflict, true
+	}
+	return nil, false
+}
+
+// witness is a lite client provider for one of the chain's witnesses
+type witness struct {
+	addr     string
+	provider litep.Provider
+}
+
+// newWitnesses builds the providers for the witnesses at addrs, they are built
+// once per chain and shared by the lite client and the witness checks
+func newWitnesses(chainID string, addrs []string) ([]witness, error) {
+	out := make([]witness, 0, len(addrs))
+	for _, addr := range addrs {
+		p, err := litehttp.New(chainID, addr)
+		if err != nil {
+			return nil, fmt.Errorf("witness %s: %w", addr, err)
+		}
+		out = append(out, witness{addr: addr, provider: p})
+	}
+	return out, nil
+}
+
+// witnessProviders returns the lite client providers for the chain's witnesses,
+// the primary is used as the only witness if the chain has none configured
+func (c *Chain) witnessProviders(primary litep.Provider) []litep.Provider {
+	if len(c.witnesses) == 0 {
+		return []litep.Provider{primary}
+	}
+
+	out := make([]litep.Provider, 0, len(c.witnesses))
+	for _, w := range c.witnesses {
+		out = append(out, w.provider)
+	}
+	return out
+}
+
+// checkWitnesses fetches the header at the same height as h from each of the chain's witnesses
+// and returns ErrConflictingHeaders if any of them has a different header that is signed by
+// enough of the validators that signed h. Witnesses that can't be reached or return invalid
+// headers or validator sets are logged and skipped, as are witnesses that haven't reached h yet.
+func (c *Chain) checkWitnesses(ctx context.Context, h *tmclient.Header) error {
+	for _, w := range c.witnesses {
+		addr := w.addr
+		var wsh *tmtypes.SignedHeader
+		err := rpcCall(ctx, func() (err error) {
+			wsh, err = w.provider.SignedHeader(h.Height)
+			return err
+		})
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case errors.Is(err, litep.ErrSignedHeaderNotFound):
+			// the witness is behind, which is expected every so often and isn't a fault
+			continue
+		case err != nil:
+			c.Error(fmt.Errorf("failed to fetch header %d from witness %s: %w", h.Height, addr, err))
+			continue
+		}
+		if bytes.Equal(wsh.Hash(), h.Hash()) {
+			continue
+		}
+
+		// the headers only conflict if the witness header is signed, otherwise the witness is faulty
+		if err = wsh.ValidateBasic(c.ChainID); err != nil {
+			c.Error(fmt.Errorf("witness %s sent an invalid header: %w", addr, err))
+			continue
+		}
+		if err = h.ValidatorSet.VerifyCommitTrusting(c.ChainID, wsh.Commit.BlockID, wsh.Height, wsh.Commit, lite.DefaultTrustLevel); err != nil {
+			c.Error(fmt.Errorf("witness %s sent a header that isn't trusted: %w", addr, err))
+			continue
+		}
+
+		var wvs *tmtypes.ValidatorSet
+		if err = rpcCall(ctx, func() (err error) {
+			wvs, err = w.provider.ValidatorSet(h.Height)
+			return err
+		}); err != nil {
+			c.Error(fmt.Errorf("failed to fetch validator set %d from witness %s: %w", h.Height, addr, err))
+			continue
+		}
+
+		// evidence built from a validator set that didn't sign the witness header is rejected
+		if !bytes.Equal(wvs.Hash(), wsh.ValidatorsHash) {
+			c.Error(fmt.Errorf("witness %s sent a validator set that doesn't match its header", addr))
+			continue
+		}
+		if err = wvs.VerifyCommit(c.ChainID, wsh.Commit.BlockID, wsh.Height, wsh.Commit); err != nil {
+			c.Error(fmt.Errorf("witness %s sent a header that isn't signed by its validator set: %w", addr, err))
+			continue
+		}
+
+		return &ErrConflictingHeaders{
+			ChainID:     c.ChainID,
+			Witness:     addr,
+			Primary:     h,
+			Conflicting: &tmclient.Header{SignedHeader: *wsh, ValidatorSet: wvs},
+		}
+	}
+	return nil
+}
+
+// SubmitMisbehaviour submits the conflicting headers for the chain src as evidence of
+// misbehaviour to dst, freezing the client for src on dst
+func (src *Chain) SubmitMisbehaviour(ctx context.Context, dst *Chain, conflict *ErrConflictingHeaders) error {
+	if conflict.ChainID != src.ChainID {
+		return fmt.Errorf("conflicting headers are for %s, not %s", conflict.ChainID, src.ChainID)
+	}
+
+	msgs := &RelayMsgs{
+		Src: []sdk.Msg{},
+		Dst: []sdk.Msg{dst.PathEnd.SubmitMisbehaviour(conflict.Primary, conflict.Conflicting, dst.MustGetAddress())},
+	}
+	if msgs.Send(ctx, src, dst); !msgs.success {
+		return fmt.Errorf("failed to submit misbehaviour for %s to [%s]client(%s)", src.ChainID, dst.ChainID, dst.PathEnd.ClientID)
+	}
+
+	if dst.dryRun {
+		return nil
+	}
+	dst.Log(fmt.Sprintf("★ Misbehaviour submitted: [%s]client(%s) for %s is frozen", dst.ChainID, dst.PathEnd.ClientID, src.ChainID))
+	return nil
+}
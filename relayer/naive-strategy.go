@@ -1,12 +1,29 @@
 package relayer
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strconv"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	chanState "github.com/cosmos/cosmos-sdk/x/ibc/04-channel/exported"
+	tmclient "github.com/cosmos/cosmos-sdk/x/ibc/07-tendermint/types"
 )
 
+func init() {
+	RegisterStrategy((&NaiveStrategy{}).GetType(), func(path *Path) (Strategy, error) {
+		if err := path.Strategy.CheckParams(retryParams...); err != nil {
+			return nil, err
+		}
+		rq, err := newRetryQueue(path.Strategy)
+		if err != nil {
+			return nil, err
+		}
+		return &NaiveStrategy{Ordered: path.Ordered(), Filter: path.Filter, retries: rq}, nil
+	})
+}
+
 // NewNaiveStrategy returns the proper config for the NaiveStrategy
 func NewNaiveStrategy() *StrategyCfg {
 	return &StrategyCfg{
@@ -17,6 +34,9 @@ func NewNaiveStrategy() *StrategyCfg {
 // NaiveStrategy is an implementation of Strategy
 type NaiveStrategy struct {
 	Ordered bool
+	Filter  *PacketFilter
+
+	retries *retryQueue
 }
 
 // GetType implements Strategy
@@ -25,30 +45,82 @@ func (nrs *NaiveStrategy) GetType() string {
 }
 
 // UnrelayedSequencesOrdered returns the unrelayed sequence numbers between two chains
-func (nrs *NaiveStrategy) UnrelayedSequencesOrdered(src, dst *Chain, sh *SyncHeaders) (*RelaySequences, error) {
-	return UnrelayedSequences(src, dst, sh)
+func (nrs *NaiveStrategy) UnrelayedSequencesOrdered(ctx context.Context, src, dst *Chain, sh *SyncHeaders) (*RelaySequences, error) {
+	return UnrelayedSequences(ctx, src, dst, sh)
 }
 
 // UnrelayedSequencesUnordered returns the unrelayed sequence numbers between two chains
-func (nrs *NaiveStrategy) UnrelayedSequencesUnordered(src, dst *Chain, sh *SyncHeaders) (*RelaySequences, error) {
-	return UnrelayedSequences(src, dst, sh)
+func (nrs *NaiveStrategy) UnrelayedSequencesUnordered(ctx context.Context, src, dst *Chain, sh *SyncHeaders) (*RelaySequences, error) {
+	return UnrelayedSequencesUnordered(ctx, src, dst, sh)
+}
+
+// UnrelayedAcknowledgements returns the sequence numbers of the acknowledgements
+// that have not been relayed back to the sending chain
+func (nrs *NaiveStrategy) UnrelayedAcknowledgements(ctx context.Context, src, dst *Chain, sh *SyncHeaders) (*RelaySequences, error) {
+	return UnrelayedAcknowledgements(ctx, src, dst, sh)
 }
 
 // HandleEvents defines how the relayer will handle block and transaction events as they are emmited
-func (nrs *NaiveStrategy) HandleEvents(src, dst *Chain, sh *SyncHeaders, events map[string][]string) {
-	rlyPackets, err := relayPacketsFromEventListener(src.PathEnd, dst.PathEnd, events)
-	if len(rlyPackets) > 0 && err == nil {
-		sendTxFromEventPackets(src, dst, rlyPackets, sh)
+func (nrs *NaiveStrategy) HandleEvents(ctx context.Context, src, dst *Chain, sh *SyncHeaders, events map[string][]string) {
+	rlyPackets, err := relayPacketsFromEventListener(src.PathEnd, dst.PathEnd, events, nrs.Filter)
+	if len(rlyPackets) == 0 || err != nil {
+		return
+	}
+
+	rlyPackets, timeouts := splitTimedOutPackets(rlyPackets, sh.GetHeader(src.ChainID), src.PathEnd.getOrder() == chanState.ORDERED)
+	rlyPackets, timeouts = src.store.observe(src, rlyPackets), dst.store.observe(dst, timeouts)
+	if len(rlyPackets) > 0 {
+		sendTxFromEventPackets(ctx, src, dst, rlyPackets, sh, nrs.retries)
 	}
+
+	// packets that have timed out on src are timed out on dst, where they were sent
+	if len(timeouts) > 0 {
+		sendTxFromEventPackets(ctx, dst, src, timeouts, sh, nrs.retries)
+	}
+}
+
+// splitTimedOutPackets separates out the packets that can no longer be received by
+// the chain with the given header and returns timeout packets for them. On ordered
+// channels a timeout closes the channel, so only the first timed out packet is timed
+// out and the packets sent after it, which can no longer be received, are dropped.
+func splitTimedOutPackets(rlyPackets []relayPacket, hdr *tmclient.Header, ordered bool) (relay, timeouts []relayPacket) {
+	var first *relayMsgRecvPacket
+	for _, rp := range rlyPackets {
+		recv, ok := rp.(*relayMsgRecvPacket)
+		switch {
+		case !ok || !recv.timedOut(hdr):
+			relay = append(relay, rp)
+		case !ordered:
+			timeouts = append(timeouts, recv.timeoutPacket())
+		case first == nil || recv.seq < first.seq:
+			first = recv
+		}
+	}
+	if first == nil {
+		return relay, timeouts
+	}
+
+	out := make([]relayPacket, 0, len(relay))
+	for _, rp := range relay {
+		if recv, ok := rp.(*relayMsgRecvPacket); ok && recv.seq > first.seq {
+			continue
+		}
+		out = append(out, rp)
+	}
+	return out, []relayPacket{first.timeoutPacket()}
 }
 
-func relayPacketsFromEventListener(src, dst *PathEnd, events map[string][]string) (rlyPkts []relayPacket, err error) {
+// relayPacketsFromEventListener returns the packets in the events that are relayed over
+// the path, packets that aren't allowed by the filter are left out
+func relayPacketsFromEventListener(src, dst *PathEnd, events map[string][]string, filter *PacketFilter) (rlyPkts []relayPacket, err error) {
 	// check for send packets
 	if pdval, ok := events["send_packet.packet_data"]; ok {
 		for i, pd := range pdval {
 			// Ensure that we only relay over the channel and port specified
-			// OPTIONAL FEATURE: add additional filtering options
-			// Example Events - "transfer.amount(sdk.Coin)", "message.sender(sdk.AccAddress)"
+			// and only the packets allowed by the path's filter
+			if !filter.Allows([]byte(pd)) {
+				continue
+			}
 			srcChan, srcPort := events["send_packet.packet_src_channel"], events["send_packet.packet_src_port"]
 			dstChan, dstPort := events["send_packet.packet_dst_channel"], events["send_packet.packet_dst_port"]
 
@@ -93,7 +165,10 @@ func relayPacketsFromEventListener(src, dst *PathEnd, events map[string][]string
 	if pdval, ok := events["recv_packet.packet_data"]; ok {
 		for i, pd := range pdval {
 			// Ensure that we only relay over the channel and port specified
-			// OPTIONAL FEATURE: add additional filtering options
+			// and only the packets allowed by the path's filter
+			if !filter.Allows([]byte(pd)) {
+				continue
+			}
 			srcChan, srcPort := events["recv_packet.packet_src_channel"], events["recv_packet.packet_src_port"]
 			dstChan, dstPort := events["recv_packet.packet_dst_channel"], events["recv_packet.packet_dst_port"]
 
@@ -140,16 +215,9 @@ func relayPacketsFromEventListener(src, dst *PathEnd, events map[string][]string
 	return
 }
 
-func sendTxFromEventPackets(src, dst *Chain, rlyPackets []relayPacket, sh *SyncHeaders) {
-	// fetch the proofs for the relayPackets
-	for _, rp := range rlyPackets {
-		if err := rp.FetchCommitResponse(src, dst, sh); err != nil {
-			// we don't expect many errors here because of the retry
-			// in FetchCommitResponse
-			src.Error(err)
-		}
-	}
-
+// sendTxFromEventPackets relays the packets to src in a single transaction, if the transaction
+// fails or leaves out packets that are already being relayed the packets are added to the retry queue
+func sendTxFromEventPackets(ctx context.Context, src, dst *Chain, rlyPackets []relayPacket, sh *SyncHeaders, rq *retryQueue) {
 	// instantiate the RelayMsgs with the appropriate update client
 	txs := &RelayMsgs{
 		Src: []sdk.Msg{
@@ -158,62 +226,145 @@ func sendTxFromEventPackets(src, dst *Chain, rlyPackets []relayPacket, sh *SyncH
 		Dst: []sdk.Msg{},
 	}
 
-	// add the packet msgs to RelayPackets
+	// fetch the proofs for the relayPackets and add the packet msgs to RelayPackets
+	sent := []relayPacket{}
 	for _, rp := range rlyPackets {
+		if err := rp.FetchCommitResponse(ctx, src, dst, sh); err != nil {
+			// we don't expect many errors here because of the retry
+			// in FetchCommitResponse
+			src.Error(err)
+			continue
+		}
 		txs.Src = append(txs.Src, rp.Msg(src, dst))
+		sent = append(sent, rp)
+	}
+
+	// nothing to send if none of the proofs could be fetched
+	if len(txs.Src) == 1 {
+		return
 	}
 
-	// send the transaction, maybe retry here if not successful
-	if txs.Send(src, dst); !txs.success {
-		src.Error(fmt.Errorf("failed to send packets, maybe we should add a retry here"))
+	// send the transaction and queue the packets to be retried if it fails or any were skipped
+	if txs.Send(ctx, src, dst); !txs.success {
+		rq.add(ctx, src, dst, sent, sh)
 	}
 }
 
-// RelayPacketsUnorderedChan creates transactions to relay un-relayed messages
-func (nrs *NaiveStrategy) RelayPacketsUnorderedChan(src, dst *Chain, sp *RelaySequences, sh *SyncHeaders) error {
-	// TODO: Implement unordered channels
-	return nrs.RelayPacketsOrderedChan(src, dst, sp, sh)
+// RelayPacketsUnorderedChan creates transactions to relay un-relayed messages. Packets
+// on unordered channels don't depend on each other, so any packet that can't be
+// fetched is skipped and left to be picked up again later
+// CONTRACT: the SyncHeaders passed in here must be up to date or being kept updated
+func (nrs *NaiveStrategy) RelayPacketsUnorderedChan(ctx context.Context, src, dst *Chain, sp *RelaySequences, sh *SyncHeaders) error {
+	return nrs.relayPackets(ctx, src, dst, sp, sh, false)
 }
 
 // RelayPacketsOrderedChan creates transactions to clear both queues
 // CONTRACT: the SyncHeaders passed in here must be up to date or being kept updated
-func (nrs *NaiveStrategy) RelayPacketsOrderedChan(src, dst *Chain, sp *RelaySequences, sh *SyncHeaders) error {
+func (nrs *NaiveStrategy) RelayPacketsOrderedChan(ctx context.Context, src, dst *Chain, sp *RelaySequences, sh *SyncHeaders) error {
+	return nrs.relayPackets(ctx, src, dst, sp, sh, true)
+}
 
-	// create the appropriate update client messages
-	msgs := &RelayMsgs{Src: []sdk.Msg{}, Dst: []sdk.Msg{}}
-	if len(sp.Src) > 0 {
-		msgs.Dst = append(msgs.Dst, dst.PathEnd.UpdateClient(sh.GetHeader(src.ChainID), dst.MustGetAddress()))
+func (nrs *NaiveStrategy) relayPackets(ctx context.Context, src, dst *Chain, sp *RelaySequences, sh *SyncHeaders, ordered bool) error {
+	msgs, err := packetRelayMsgs(ctx, src, dst, sp, sh, ordered, nrs.Filter)
+	if err != nil {
+		return err
+	}
+
+	if !msgs.Ready() {
+		src.Log(fmt.Sprintf("- No packets to relay between [%s]port{%s} and [%s]port{%s}", src.ChainID, src.PathEnd.PortID, dst.ChainID, dst.PathEnd.PortID))
+		return nil
+	}
+
+	sendRelayMsgs(ctx, src, dst, msgs, sh)
+	return nil
+}
+
+// RelayAcknowledgements creates transactions to relay the acknowledgements written on each
+// chain back to the chain that sent the packet. Any ack that can't be fetched is skipped and
+// left to be picked up again later
+// CONTRACT: the SyncHeaders passed in here must be up to date or being kept updated
+func (nrs *NaiveStrategy) RelayAcknowledgements(ctx context.Context, src, dst *Chain, sp *RelaySequences, sh *SyncHeaders) error {
+	msgs := ackRelayMsgs(ctx, src, dst, sp, sh, nrs.Filter)
+	if !msgs.Ready() {
+		src.Log(fmt.Sprintf("- No acknowledgements to relay between [%s]port{%s} and [%s]port{%s}", src.ChainID, src.PathEnd.PortID, dst.ChainID, dst.PathEnd.PortID))
+		return nil
 	}
-	if len(sp.Dst) > 0 {
-		msgs.Src = append(msgs.Src, src.PathEnd.UpdateClient(sh.GetHeader(dst.ChainID), src.MustGetAddress()))
+
+	sendRelayMsgs(ctx, src, dst, msgs, sh)
+	return nil
+}
+
+// packetRelayMsgs returns the msgs to relay the packets with the given sequences in both directions,
+// if ordered is false a packet that fails to be fetched is skipped rather than returning an error.
+// Packets that aren't allowed by the filter are skipped. The msgs returned don't include the
+// update client messages.
+func packetRelayMsgs(ctx context.Context, src, dst *Chain, sp *RelaySequences, sh *SyncHeaders, ordered bool, filter *PacketFilter) (*RelayMsgs, error) {
+	// add messages for src -> dst, and timeouts for packets sent from src
+	srcRecvs, srcTimeouts, err := packetMsgsFromSequences(ctx, src, dst, sh, sp.Src, ordered, filter)
+	if err != nil {
+		return nil, err
 	}
 
-	// add messages for src -> dst
+	// add messages for dst -> src, and timeouts for packets sent from dst
+	dstRecvs, dstTimeouts, err := packetMsgsFromSequences(ctx, dst, src, sh, sp.Dst, ordered, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RelayMsgs{
+		Src: append(dstRecvs, srcTimeouts...),
+		Dst: append(srcRecvs, dstTimeouts...),
+	}, nil
+}
+
+// ackRelayMsgs returns the msgs to relay the acknowledgements with the given sequences in both
+// directions, skipping any that fail to be fetched or whose packets aren't allowed by the filter.
+// The msgs returned don't include the update client messages.
+func ackRelayMsgs(ctx context.Context, src, dst *Chain, sp *RelaySequences, sh *SyncHeaders, filter *PacketFilter) *RelayMsgs {
+	msgs := &RelayMsgs{Src: []sdk.Msg{}, Dst: []sdk.Msg{}}
+
+	// add messages for acks written on src, these are sent to dst
 	for _, seq := range sp.Src {
-		msg, err := packetMsgFromTxQuery(src, dst, sh, seq)
-		if err != nil {
-			return err
+		msg, err := ackMsgFromTxQuery(ctx, src, dst, sh, seq, filter)
+		switch {
+		case errors.Is(err, errPacketFiltered):
+			src.Log(fmt.Sprintf("- skipping ack seq(%d) on [%s]: %s", seq, src.ChainID, err))
+			continue
+		case err != nil:
+			src.Error(fmt.Errorf("skipping ack seq(%d): %w", seq, err))
+			continue
 		}
 		msgs.Dst = append(msgs.Dst, msg)
 	}
 
-	// add messages for dst -> src
+	// add messages for acks written on dst, these are sent to src
 	for _, seq := range sp.Dst {
-		msg, err := packetMsgFromTxQuery(dst, src, sh, seq)
-		if err != nil {
-			return err
+		msg, err := ackMsgFromTxQuery(ctx, dst, src, sh, seq, filter)
+		switch {
+		case errors.Is(err, errPacketFiltered):
+			dst.Log(fmt.Sprintf("- skipping ack seq(%d) on [%s]: %s", seq, dst.ChainID, err))
+			continue
+		case err != nil:
+			dst.Error(fmt.Errorf("skipping ack seq(%d): %w", seq, err))
+			continue
 		}
 		msgs.Src = append(msgs.Src, msg)
 	}
 
-	if !msgs.Ready() {
-		src.Log(fmt.Sprintf("- No packets to relay between [%s]port{%s} and [%s]port{%s}", src.ChainID, src.PathEnd.PortID, dst.ChainID, dst.PathEnd.PortID))
-		return nil
+	return msgs
+}
+
+// sendRelayMsgs prepends the appropriate update client messages to msgs and sends them
+func sendRelayMsgs(ctx context.Context, src, dst *Chain, msgs *RelayMsgs, sh *SyncHeaders) {
+	if len(msgs.Dst) > 0 {
+		msgs.Dst = append([]sdk.Msg{dst.PathEnd.UpdateClient(sh.GetHeader(src.ChainID), dst.MustGetAddress())}, msgs.Dst...)
+	}
+	if len(msgs.Src) > 0 {
+		msgs.Src = append([]sdk.Msg{src.PathEnd.UpdateClient(sh.GetHeader(dst.ChainID), src.MustGetAddress())}, msgs.Src...)
 	}
 
-	// TODO: increase the amount of gas as the number of messages increases
-	// notify the user of that
-	if msgs.Send(src, dst); msgs.success {
+	// NOTE: the gas only scales with the number of messages if simulate-gas is set on the chain
+	if msgs.Send(ctx, src, dst); msgs.success {
 		if len(msgs.Dst) > 1 {
 			dst.logPacketsRelayed(src, len(msgs.Dst)-1)
 		}
@@ -221,18 +372,157 @@ func (nrs *NaiveStrategy) RelayPacketsOrderedChan(src, dst *Chain, sp *RelaySequ
 			src.logPacketsRelayed(dst, len(msgs.Src)-1)
 		}
 	}
+}
 
-	return nil
+// packetMsgsFromSequences returns the msgs to relay the packets sent from src with the given
+// sequences, recvs are to be sent to dst and timeouts, for the packets that have timed out
+// on dst, are to be sent to src. If ordered is false, packets that can't be fetched are skipped.
+// On ordered channels the packets after one that can't be fetched can't be received, so the
+// msgs stop at the first gap in the sequences. Packets that aren't allowed by the filter are
+// skipped, on ordered channels the packets after them are left out as well.
+func packetMsgsFromSequences(ctx context.Context, src, dst *Chain, sh *SyncHeaders, seqs []uint64, ordered bool, filter *PacketFilter) (recvs, timeouts []sdk.Msg, err error) {
+	recvs, timeouts = []sdk.Msg{}, []sdk.Msg{}
+	if ordered {
+		if seqs, err = contiguousSequences(ctx, src, dst, sh, seqs); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for _, seq := range seqs {
+		msg, timedOut, err := packetMsgFromTxQuery(ctx, src, dst, sh, seq, filter)
+		switch {
+		case errors.Is(err, errPacketFiltered) && ordered:
+			src.Log(fmt.Sprintf("- skipping packets from seq(%d) on [%s]: %s", seq, src.ChainID, err))
+			return recvs, timeouts, nil
+		case errors.Is(err, errPacketFiltered):
+			src.Log(fmt.Sprintf("- skipping packet seq(%d) on [%s]: %s", seq, src.ChainID, err))
+		case err != nil && ordered:
+			src.Error(fmt.Errorf("stopping at missing packet seq(%d) on [%s]chan{%s}, the packets after it can't be received until it is relayed: %w",
+				seq, src.ChainID, src.PathEnd.ChannelID, err))
+			return recvs, timeouts, nil
+		case err != nil:
+			src.Error(fmt.Errorf("skipping packet seq(%d): %w", seq, err))
+		case timedOut && ordered:
+			// the timeout closes the channel, so none of the packets after it can be relayed
+			src.Log(fmt.Sprintf("- packet seq(%d) on [%s]chan{%s} has timed out, the packets after it can't be received",
+				seq, src.ChainID, src.PathEnd.ChannelID))
+			return recvs, append(timeouts, msg), nil
+		case timedOut:
+			timeouts = append(timeouts, msg)
+		default:
+			recvs = append(recvs, msg)
+		}
+	}
+	return recvs, timeouts, nil
+}
+
+// contiguousSequences returns the sequences of the packets sent from src over an ordered channel
+// that dst can receive in order, starting exactly at dst's next sequence to receive. Sequences
+// that have already been received are dropped and the rest are cut off at the first gap.
+func contiguousSequences(ctx context.Context, src, dst *Chain, sh *SyncHeaders, seqs []uint64) ([]uint64, error) {
+	if len(seqs) == 0 {
+		return seqs, nil
+	}
+
+	recvRes, err := dst.QueryNextSeqRecv(ctx, int64(sh.GetHeight(dst.ChainID)))
+	if err != nil {
+		return nil, err
+	}
+
+	out, gap := contiguousFrom(recvRes.NextSequenceRecv, seqs)
+	if gap != 0 {
+		src.Error(fmt.Errorf("stopping before packet seq(%d) on [%s]chan{%s}, seq(%d) is the next one [%s] can receive but it isn't in the sequences to relay",
+			gap, src.ChainID, src.PathEnd.ChannelID, recvRes.NextSequenceRecv+uint64(len(out)), dst.ChainID))
+	}
+	return out, nil
+}
+
+// contiguousFrom returns the sorted seqs that follow on from next without a gap, dropping the ones
+// before next. If the seqs stop at a gap, gap is the first sequence after it, otherwise it is 0.
+func contiguousFrom(next uint64, seqs []uint64) (out []uint64, gap uint64) {
+	out = make([]uint64, 0, len(seqs))
+	for _, seq := range seqs {
+		switch {
+		case seq < next:
+			// already received
+			continue
+		case seq > next:
+			return out, seq
+		}
+		out = append(out, seq)
+		next++
+	}
+	return out, 0
 }
 
-// packetMsgFromTxQuery returns a sdk.Msg to relay a packet with a given seq on src
-func packetMsgFromTxQuery(src, dst *Chain, sh *SyncHeaders, seq uint64) (sdk.Msg, error) {
+// packetMsgFromTxQuery returns a sdk.Msg to relay a packet with a given seq on src. If the packet
+// has timed out on dst, the msg is a MsgTimeout to be sent to src and timedOut is true, otherwise
+// it is a MsgPacket to be sent to dst. If the packet isn't allowed by the filter errPacketFiltered
+// is returned.
+func packetMsgFromTxQuery(ctx context.Context, src, dst *Chain, sh *SyncHeaders, seq uint64, filter *PacketFilter) (msg sdk.Msg, timedOut bool, err error) {
 	eveSend, err := ParseEvents(fmt.Sprintf(defaultPacketSendQuery, src.PathEnd.ChannelID, seq))
+	if err != nil {
+		return nil, false, err
+	}
+
+	tx, err := src.QueryTxs(ctx, sh.GetHeight(src.ChainID), 1, 1000, eveSend)
+	switch {
+	case err != nil:
+		return nil, false, fmt.Errorf("failed to query the tx that sent the packet: %w", err)
+	case tx.Count == 0:
+		return nil, false, fmt.Errorf("no tx that sent the packet was found, it may be missing from the node's tx index")
+	case tx.Count > 1:
+		return nil, false, fmt.Errorf("%d txs that sent the packet were found", tx.Count)
+	}
+
+	rlyPackets, err := relayPacketFromQueryResponse(src.PathEnd, dst.PathEnd, tx.Txs[0])
+	switch {
+	case err != nil:
+		return nil, false, err
+	case len(rlyPackets) == 0:
+		return nil, false, fmt.Errorf("no relay msgs created from query response")
+	case len(rlyPackets) > 1:
+		return nil, false, fmt.Errorf("more than one relay msg found in tx query")
+	}
+
+	// sanity check the sequence number against the one we are querying for
+	// TODO: move this into relayPacketFromQueryResponse?
+	if seq != rlyPackets[0].Seq() {
+		return nil, false, fmt.Errorf("Different sequence number from query (%d vs %d)", seq, rlyPackets[0].Seq())
+	}
+
+	if !filter.Allows(rlyPackets[0].Data()) {
+		return nil, false, errPacketFiltered
+	}
+
+	// if the packet can no longer be received, prove that on dst and time it out on src
+	if rp, ok := rlyPackets[0].(*relayMsgRecvPacket); ok && rp.timedOut(sh.GetHeader(dst.ChainID)) {
+		tp := rp.timeoutPacket()
+		if err = tp.FetchCommitResponse(ctx, src, dst, sh); err != nil {
+			return nil, false, err
+		}
+		return tp.Msg(src, dst), true, nil
+	}
+
+	// fetch the proof from the sending chain
+	if err = rlyPackets[0].FetchCommitResponse(ctx, dst, src, sh); err != nil {
+		return nil, false, err
+	}
+
+	// return the sending msg
+	return rlyPackets[0].Msg(dst, src), false, nil
+}
+
+// ackMsgFromTxQuery returns a sdk.Msg to relay the acknowledgement that src wrote
+// for the packet with a given seq sent from dst, the msg is to be sent to dst. If the
+// packet isn't allowed by the filter errPacketFiltered is returned.
+func ackMsgFromTxQuery(ctx context.Context, src, dst *Chain, sh *SyncHeaders, seq uint64, filter *PacketFilter) (sdk.Msg, error) {
+	eveRecv, err := ParseEvents(fmt.Sprintf(defaultPacketAckQuery, dst.PathEnd.ChannelID, seq))
 	if err != nil {
 		return nil, err
 	}
 
-	tx, err := src.QueryTxs(sh.GetHeight(src.ChainID), 1, 1000, eveSend)
+	tx, err := src.QueryTxs(ctx, sh.GetHeight(src.ChainID), 1, 1000, eveRecv)
 	switch {
 	case err != nil:
 		return nil, err
@@ -242,7 +532,8 @@ func packetMsgFromTxQuery(src, dst *Chain, sh *SyncHeaders, seq uint64) (sdk.Msg
 		return nil, fmt.Errorf("more than one transaction returned with query")
 	}
 
-	rlyPackets, err := relayPacketFromQueryResponse(src.PathEnd, dst.PathEnd, tx.Txs[0])
+	// NOTE: dst sent the packet so it is the source of the packet here
+	rlyPackets, err := ackPacketFromQueryResponse(dst.PathEnd, src.PathEnd, tx.Txs[0])
 	switch {
 	case err != nil:
 		return nil, err
@@ -253,17 +544,20 @@ func packetMsgFromTxQuery(src, dst *Chain, sh *SyncHeaders, seq uint64) (sdk.Msg
 	}
 
 	// sanity check the sequence number against the one we are querying for
-	// TODO: move this into relayPacketFromQueryResponse?
 	if seq != rlyPackets[0].Seq() {
 		return nil, fmt.Errorf("Different sequence number from query (%d vs %d)", seq, rlyPackets[0].Seq())
 	}
 
-	// fetch the proof from the sending chain
-	if err = rlyPackets[0].FetchCommitResponse(dst, src, sh); err != nil {
+	if !filter.Allows(rlyPackets[0].Data()) {
+		return nil, errPacketFiltered
+	}
+
+	// fetch the ack proof from the receiving chain
+	if err = rlyPackets[0].FetchCommitResponse(ctx, dst, src, sh); err != nil {
 		return nil, err
 	}
 
-	// return the sending msg
+	// return the ack msg
 	return rlyPackets[0].Msg(dst, src), nil
 }
 
@@ -331,3 +625,71 @@ func relayPacketFromQueryResponse(src, dst *PathEnd, res sdk.TxResponse) (rlyPac
 
 	return nil, fmt.Errorf("no packet data found")
 }
+
+// ackPacketFromQueryResponse looks through the recv_packet events in a sdk.Response
+// and returns relayPackets to acknowledge the packets sent from src to dst
+func ackPacketFromQueryResponse(src, dst *PathEnd, res sdk.TxResponse) (rlyPackets []relayPacket, err error) {
+	for _, l := range res.Logs {
+		for _, e := range l.Events {
+			if e.Type == "recv_packet" {
+				// NOTE: Src and Dst are not switched here
+				rp := &relayMsgPacketAck{pass: false}
+				for _, p := range e.Attributes {
+					if p.Key == "packet_src_channel" {
+						if p.Value != src.ChannelID {
+							rp.pass = true
+							continue
+						}
+					}
+					if p.Key == "packet_dst_channel" {
+						if p.Value != dst.ChannelID {
+							rp.pass = true
+							continue
+						}
+					}
+					if p.Key == "packet_src_port" {
+						if p.Value != src.PortID {
+							rp.pass = true
+							continue
+						}
+					}
+					if p.Key == "packet_dst_port" {
+						if p.Value != dst.PortID {
+							rp.pass = true
+							continue
+						}
+					}
+					if p.Key == "packet_data" {
+						rp.packetData = []byte(p.Value)
+					}
+					if p.Key == "packet_ack" {
+						rp.ack = []byte(p.Value)
+					}
+					if p.Key == "packet_timeout_height" {
+						timeout, _ := strconv.ParseUint(p.Value, 10, 64)
+						rp.timeout = timeout
+					}
+					if p.Key == "packet_timeout_timestamp" {
+						timeout, _ := strconv.ParseUint(p.Value, 10, 64)
+						rp.timeoutStamp = timeout
+					}
+					if p.Key == "packet_sequence" {
+						seq, _ := strconv.ParseUint(p.Value, 10, 64)
+						rp.seq = seq
+					}
+				}
+
+				// if we have decided not to relay this packet, don't add it
+				if !rp.pass {
+					rlyPackets = append(rlyPackets, rp)
+				}
+			}
+		}
+	}
+
+	if len(rlyPackets) > 0 {
+		return
+	}
+
+	return nil, fmt.Errorf("no packet acknowledgement found")
+}
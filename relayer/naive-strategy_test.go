@@ -0,0 +1,78 @@
+package relayer
+
+import (
+	"testing"
+
+	tmclient "github.com/cosmos/cosmos-sdk/x/ibc/07-tendermint/types"
+	"github.com/stretchr/testify/require"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+func TestContiguousFrom(t *testing.T) {
+	cases := []struct {
+		name     string
+		next     uint64
+		seqs     []uint64
+		expected []uint64
+		gap      uint64
+	}{
+		{"no sequences", 1, []uint64{}, []uint64{}, 0},
+		{"contiguous", 1, []uint64{1, 2, 3}, []uint64{1, 2, 3}, 0},
+		{"already received", 3, []uint64{1, 2, 3, 4}, []uint64{3, 4}, 0},
+		{"all received", 5, []uint64{1, 2, 3, 4}, []uint64{}, 0},
+		{"gap in the middle", 1, []uint64{1, 2, 4, 5}, []uint64{1, 2}, 4},
+		{"gap at the start", 2, []uint64{3, 4}, []uint64{}, 3},
+		{"gap after received", 2, []uint64{1, 2, 5}, []uint64{2}, 5},
+		{"duplicate", 1, []uint64{1, 1, 2}, []uint64{1, 2}, 0},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			out, gap := contiguousFrom(tc.next, tc.seqs)
+			require.Equal(t, tc.expected, out)
+			require.Equal(t, tc.gap, gap)
+		})
+	}
+}
+
+func TestSplitTimedOutPackets(t *testing.T) {
+	var (
+		hdr  = &tmclient.Header{SignedHeader: tmtypes.SignedHeader{Header: &tmtypes.Header{Height: 10}}}
+		live = func(seq uint64) relayPacket { return &relayMsgRecvPacket{seq: seq, timeout: 20} }
+		late = func(seq uint64) relayPacket { return &relayMsgRecvPacket{seq: seq, timeout: 5} }
+		ack  = &relayMsgPacketAck{seq: 1}
+		seqs = func(rps []relayPacket) []uint64 {
+			out := []uint64{}
+			for _, rp := range rps {
+				out = append(out, rp.Seq())
+			}
+			return out
+		}
+	)
+
+	cases := []struct {
+		name     string
+		packets  []relayPacket
+		ordered  bool
+		relay    []uint64
+		timeouts []uint64
+	}{
+		{"none timed out", []relayPacket{live(1), live(2)}, false, []uint64{1, 2}, []uint64{}},
+		{"unordered", []relayPacket{live(1), late(2), live(3), late(4)}, false, []uint64{1, 3}, []uint64{2, 4}},
+		{"ordered none timed out", []relayPacket{live(1), live(2)}, true, []uint64{1, 2}, []uint64{}},
+		{"ordered stops at the first timeout", []relayPacket{live(1), late(2), live(3), late(4)}, true, []uint64{1}, []uint64{2}},
+		{"ordered out of order", []relayPacket{late(4), live(1), late(2), live(3)}, true, []uint64{1}, []uint64{2}},
+		{"ordered keeps acks", []relayPacket{late(1), ack, live(2)}, true, []uint64{1}, []uint64{1}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			relay, timeouts := splitTimedOutPackets(tc.packets, hdr, tc.ordered)
+			require.Equal(t, tc.relay, seqs(relay))
+			require.Equal(t, tc.timeouts, seqs(timeouts))
+			for _, rp := range timeouts {
+				require.IsType(t, &relayMsgTimeout{}, rp)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package relayer
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -16,69 +17,18 @@ var (
 	defaultIBCVersion      = "1.0.0"
 	defaultIBCVersions     = []string{defaultIBCVersion}
 	defaultTransferVersion = "ics20-1"
-	defaultUnbondingTime   = time.Hour * 504 // 3 weeks in hours
 	defaultMaxClockDrift   = time.Second * 10
 	defaultPacketTimeout   = 1000
 	defaultPacketSendQuery = "send_packet.packet_src_channel=%s&send_packet.packet_sequence=%d"
-	// defaultPacketAckQuery  = "recv_packet.packet_src_channel=%s&recv_packet.packet_sequence=%d"
+	defaultPacketAckQuery  = "recv_packet.packet_src_channel=%s&recv_packet.packet_sequence=%d"
 )
 
 func defaultPacketTimeoutStamp() uint64 {
 	return uint64(time.Now().Add(time.Hour * 12).UnixNano())
 }
 
-// RelayPacketsOrderedChan creates transactions to clear both queues
-// CONTRACT: the SyncHeaders passed in here must be up to date or being kept updated
-func RelayPacketsOrderedChan(src, dst *Chain, sh *SyncHeaders, sp *RelaySequences) error {
-
-	// create the appropriate update client messages
-	msgs := &RelayMsgs{Src: []sdk.Msg{}, Dst: []sdk.Msg{}}
-	if len(sp.Src) > 0 {
-		msgs.Dst = append(msgs.Dst, dst.PathEnd.UpdateClient(sh.GetHeader(src.ChainID), dst.MustGetAddress()))
-	}
-	if len(sp.Dst) > 0 {
-		msgs.Src = append(msgs.Src, src.PathEnd.UpdateClient(sh.GetHeader(dst.ChainID), src.MustGetAddress()))
-	}
-
-	// add messages for src -> dst
-	for _, seq := range sp.Src {
-		msg, err := packetMsgFromTxQuery(src, dst, sh, seq)
-		if err != nil {
-			return err
-		}
-		msgs.Dst = append(msgs.Dst, msg)
-	}
-
-	// add messages for dst -> src
-	for _, seq := range sp.Dst {
-		msg, err := packetMsgFromTxQuery(dst, src, sh, seq)
-		if err != nil {
-			return err
-		}
-		msgs.Src = append(msgs.Src, msg)
-	}
-
-	if !msgs.Ready() {
-		src.Log(fmt.Sprintf("- No packets to relay between [%s]port{%s} and [%s]port{%s}", src.ChainID, src.PathEnd.PortID, dst.ChainID, dst.PathEnd.PortID))
-		return nil
-	}
-
-	// TODO: increase the amount of gas as the number of messages increases
-	// notify the user of that
-	if msgs.Send(src, dst); msgs.success {
-		if len(msgs.Dst) > 1 {
-			dst.logPacketsRelayed(src, len(msgs.Dst)-1)
-		}
-		if len(msgs.Src) > 1 {
-			src.logPacketsRelayed(dst, len(msgs.Src)-1)
-		}
-	}
-
-	return nil
-}
-
 // SendTransferBothSides sends a ICS20 packet from src to dst
-func (src *Chain) SendTransferBothSides(dst *Chain, amount sdk.Coin, dstAddr sdk.AccAddress, source bool) error {
+func (src *Chain) SendTransferBothSides(ctx context.Context, dst *Chain, amount sdk.Coin, dstAddr sdk.AccAddress, source bool) error {
 
 	if source {
 		amount.Denom = fmt.Sprintf("%s/%s/%s", dst.PathEnd.PortID, dst.PathEnd.ChannelID, amount.Denom)
@@ -86,7 +36,7 @@ func (src *Chain) SendTransferBothSides(dst *Chain, amount sdk.Coin, dstAddr sdk
 		amount.Denom = fmt.Sprintf("%s/%s/%s", src.PathEnd.PortID, src.PathEnd.ChannelID, amount.Denom)
 	}
 
-	dstHeader, err := dst.UpdateLiteWithHeader()
+	dstHeader, err := dst.UpdateLiteWithHeader(ctx)
 	if err != nil {
 		return err
 	}
@@ -106,7 +56,7 @@ func (src *Chain) SendTransferBothSides(dst *Chain, amount sdk.Coin, dstAddr sdk
 		Dst: []sdk.Msg{},
 	}
 
-	if txs.Send(src, dst); !txs.Success() {
+	if txs.Send(ctx, src, dst); !txs.Success() {
 		return fmt.Errorf("failed to send first transaction")
 	}
 
@@ -121,22 +71,22 @@ func (src *Chain) SendTransferBothSides(dst *Chain, amount sdk.Coin, dstAddr sdk
 	)
 
 	if err = retry.Do(func() error {
-		hs, err = UpdatesWithHeaders(src, dst)
+		hs, err = UpdatesWithHeaders(ctx, src, dst)
 		if err != nil {
 			return err
 		}
 
-		seqRecv, err = dst.QueryNextSeqRecv(hs[dst.ChainID].Height)
+		seqRecv, err = dst.QueryNextSeqRecv(ctx, hs[dst.ChainID].Height)
 		if err != nil {
 			return err
 		}
 
-		seqSend, err = src.QueryNextSeqSend(hs[src.ChainID].Height)
+		seqSend, err = src.QueryNextSeqSend(ctx, hs[src.ChainID].Height)
 		if err != nil {
 			return err
 		}
 
-		srcCommitRes, err = src.QueryPacketCommitment(hs[src.ChainID].Height-1, int64(seqSend-1))
+		srcCommitRes, err = src.QueryPacketCommitment(ctx, hs[src.ChainID].Height-1, int64(seqSend-1))
 		if err != nil {
 			return err
 		}
@@ -146,7 +96,7 @@ func (src *Chain) SendTransferBothSides(dst *Chain, amount sdk.Coin, dstAddr sdk
 		}
 
 		return nil
-	}); err != nil {
+	}, retryUntilDone(ctx)); err != nil {
 		return err
 	}
 
@@ -186,12 +136,12 @@ func (src *Chain) SendTransferBothSides(dst *Chain, amount sdk.Coin, dstAddr sdk
 		Src: []sdk.Msg{},
 	}
 
-	txs.Send(src, dst)
+	txs.Send(ctx, src, dst)
 	return nil
 }
 
 // SendTransferMsg initiates an ibs20 transfer from src to dst with the specified args
-func (src *Chain) SendTransferMsg(dst *Chain, amount sdk.Coin, dstAddr sdk.AccAddress, source bool) error {
+func (src *Chain) SendTransferMsg(ctx context.Context, dst *Chain, amount sdk.Coin, dstAddr sdk.AccAddress, source bool) error {
 
 	if source {
 		amount.Denom = fmt.Sprintf("%s/%s/%s", dst.PathEnd.PortID, dst.PathEnd.ChannelID, amount.Denom)
@@ -199,7 +149,7 @@ func (src *Chain) SendTransferMsg(dst *Chain, amount sdk.Coin, dstAddr sdk.AccAd
 		amount.Denom = fmt.Sprintf("%s/%s/%s", src.PathEnd.PortID, src.PathEnd.ChannelID, amount.Denom)
 	}
 
-	dstHeader, err := dst.UpdateLiteWithHeader()
+	dstHeader, err := dst.UpdateLiteWithHeader(ctx)
 	if err != nil {
 		return err
 	}
@@ -217,15 +167,15 @@ func (src *Chain) SendTransferMsg(dst *Chain, amount sdk.Coin, dstAddr sdk.AccAd
 		Dst: []sdk.Msg{},
 	}
 
-	if txs.Send(src, dst); !txs.success {
+	if txs.Send(ctx, src, dst); !txs.success {
 		return fmt.Errorf("failed to send transfer message")
 	}
 	return nil
 }
 
 // SendPacket sends arbitrary bytes from src to dst
-func (src *Chain) SendPacket(dst *Chain, packetData []byte) error {
-	dstHeader, err := dst.UpdateLiteWithHeader()
+func (src *Chain) SendPacket(ctx context.Context, dst *Chain, packetData []byte) error {
+	dstHeader, err := dst.UpdateLiteWithHeader(ctx)
 	if err != nil {
 		return err
 	}
@@ -242,7 +192,7 @@ func (src *Chain) SendPacket(dst *Chain, packetData []byte) error {
 		Dst: []sdk.Msg{},
 	}
 
-	if txs.Send(src, dst); !txs.success {
+	if txs.Send(ctx, src, dst); !txs.success {
 		return fmt.Errorf("failed to send packet")
 	}
 	return nil
@@ -1,7 +1,9 @@
 package relayer
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	chanState "github.com/cosmos/cosmos-sdk/x/ibc/04-channel/exported"
 	tmclient "github.com/cosmos/cosmos-sdk/x/ibc/07-tendermint/types"
@@ -83,9 +85,51 @@ func (p Paths) PathsFromChains(src, dst string) (Paths, error) {
 // Path represents a pair of chains and the identifiers needed to
 // relay over them
 type Path struct {
-	Src      *PathEnd     `yaml:"src" json:"src"`
-	Dst      *PathEnd     `yaml:"dst" json:"dst"`
-	Strategy *StrategyCfg `yaml:"strategy" json:"strategy"`
+	Src      *PathEnd      `yaml:"src" json:"src"`
+	Dst      *PathEnd      `yaml:"dst" json:"dst"`
+	Strategy *StrategyCfg  `yaml:"strategy" json:"strategy"`
+	Filter   *PacketFilter `yaml:"filter,omitempty" json:"filter,omitempty"`
+
+	// ReconcileInterval is how often the relayer checks for packets the events missed,
+	// defaults to 5m if unset and is disabled by 0
+	ReconcileInterval string `yaml:"reconcile-interval,omitempty" json:"reconcile-interval,omitempty"`
+
+	// ClientRefresh is the fraction of the trusting period that can pass since a client's
+	// latest header before the client is updated, defaults to 2/3 if unset
+	ClientRefresh float64 `yaml:"client-refresh,omitempty" json:"client-refresh,omitempty"`
+
+	// MaxClockDrift is the max clock drift allowed by the clients created for
+	// the path, defaults to 10s if unset
+	MaxClockDrift string `yaml:"max-clock-drift,omitempty" json:"max-clock-drift,omitempty"`
+
+	// Link records the progress of rly tx link so that it resumes where it stopped
+	Link *LinkProgress `yaml:"link,omitempty" json:"link,omitempty"`
+}
+
+// GetClientRefresh returns the fraction of the trusting period after which the path's clients are updated
+func (p *Path) GetClientRefresh() float64 {
+	if p.ClientRefresh == 0 {
+		return defaultClientRefresh
+	}
+	return p.ClientRefresh
+}
+
+// GetMaxClockDrift returns the max clock drift allowed by the path's clients
+func (p *Path) GetMaxClockDrift() time.Duration {
+	if p.MaxClockDrift == "" {
+		return defaultMaxClockDrift
+	}
+	mcd, _ := time.ParseDuration(p.MaxClockDrift)
+	return mcd
+}
+
+// GetReconcileInterval returns the reconcile interval for the path
+func (p *Path) GetReconcileInterval() time.Duration {
+	if p.ReconcileInterval == "" {
+		return defaultReconcileInterval
+	}
+	ri, _ := time.ParseDuration(p.ReconcileInterval)
+	return ri
 }
 
 // Ordered returns true if the path is ordered and false if otherwise
@@ -104,6 +148,30 @@ func (p *Path) Validate() (err error) {
 	if _, err = p.GetStrategy(); err != nil {
 		return err
 	}
+	if err = p.Filter.Validate(); err != nil {
+		return fmt.Errorf("invalid filter: %w", err)
+	}
+	if p.ReconcileInterval != "" {
+		ri, err := time.ParseDuration(p.ReconcileInterval)
+		if err != nil {
+			return fmt.Errorf("failed to parse reconcile interval (%s): %w", p.ReconcileInterval, err)
+		}
+		if ri < 0 {
+			return fmt.Errorf("reconcile interval can't be negative, got %s", ri)
+		}
+	}
+	if p.MaxClockDrift != "" {
+		mcd, err := time.ParseDuration(p.MaxClockDrift)
+		if err != nil {
+			return fmt.Errorf("failed to parse max clock drift (%s): %w", p.MaxClockDrift, err)
+		}
+		if mcd <= 0 {
+			return fmt.Errorf("max clock drift must be positive, got %s", mcd)
+		}
+	}
+	if p.ClientRefresh < 0 || p.ClientRefresh >= 1 {
+		return fmt.Errorf("client refresh must be between 0 and 1, got %v", p.ClientRefresh)
+	}
 	if p.Src.Order != p.Dst.Order {
 		return fmt.Errorf("Both sides must have same order ('ORDERED' or 'UNORDERED'), got src(%s) and dst(%s)", p.Src.Order, p.Dst.Order)
 	}
@@ -152,14 +220,14 @@ func GenPath(srcChainID, dstChainID, srcPortID, dstPortID, order string) *Path {
 }
 
 // FindPaths returns all the open paths that exist between chains
-func FindPaths(chains Chains) (*Paths, error) {
+func FindPaths(ctx context.Context, chains Chains) (*Paths, error) {
 	var out = &Paths{}
-	hs, err := QueryLatestHeights(chains...)
+	hs, err := QueryLatestHeights(ctx, chains...)
 	if err != nil {
 		return nil, err
 	}
 	for _, src := range chains {
-		clients, err := src.QueryClients(1, 1000)
+		clients, err := src.QueryClients(ctx, 1, 1000)
 		if err != nil {
 			return nil, err
 		}
@@ -177,7 +245,7 @@ func FindPaths(chains Chains) (*Paths, error) {
 				return nil, err
 			}
 
-			conns, err := src.QueryConnectionsUsingClient(hs[src.ChainID])
+			conns, err := src.QueryConnectionsUsingClient(ctx, hs[src.ChainID])
 			if err != nil {
 				return nil, err
 			}
@@ -186,12 +254,12 @@ func FindPaths(chains Chains) (*Paths, error) {
 				if err = src.AddPath(client.GetID(), connid, dcha, dpor, "ORDERED"); err != nil {
 					return nil, err
 				}
-				conn, err := src.QueryConnection(hs[src.ChainID])
+				conn, err := src.QueryConnection(ctx, hs[src.ChainID])
 				if err != nil {
 					return nil, err
 				}
 				if conn.Connection.Connection.GetState().String() == "OPEN" {
-					chans, err := src.QueryConnectionChannels(connid, 1, 1000)
+					chans, err := src.QueryConnectionChannels(ctx, connid, 1, 1000)
 					if err != nil {
 						return nil, err
 					}
@@ -41,18 +41,33 @@ func (src *PathEnd) UpdateClient(dstHeader *tmclient.Header, signer sdk.AccAddre
 	)
 }
 
-// CreateClient creates an sdk.Msg to update the client on src with consensus state from dst
-func (src *PathEnd) CreateClient(dstHeader *tmclient.Header, trustingPeriod time.Duration, signer sdk.AccAddress) sdk.Msg {
+// SubmitMisbehaviour creates an sdk.Msg to freeze the client on src with evidence of two
+// conflicting headers from dst
+func (src *PathEnd) SubmitMisbehaviour(dstHeader1, dstHeader2 *tmclient.Header, signer sdk.AccAddress) sdk.Msg {
+	return tmclient.NewMsgSubmitClientMisbehaviour(
+		tmclient.Evidence{
+			ClientID: src.ClientID,
+			Header1:  *dstHeader1,
+			Header2:  *dstHeader2,
+			ChainID:  dstHeader1.ChainID,
+		},
+		signer,
+	)
+}
+
+// CreateClient creates an sdk.Msg to create the client on src with consensus state from dst,
+// the unbonding period must be dst's and the trusting period must be shorter than it
+func (src *PathEnd) CreateClient(dstHeader *tmclient.Header, trustingPeriod, unbondingPeriod, maxClockDrift time.Duration,
+	signer sdk.AccAddress) sdk.Msg {
 	if err := dstHeader.ValidateBasic(dstHeader.ChainID); err != nil {
 		panic(err)
 	}
-	// TODO: figure out how to dynmaically set unbonding time
 	return tmclient.NewMsgCreateClient(
 		src.ClientID,
 		*dstHeader,
 		trustingPeriod,
-		defaultUnbondingTime,
-		defaultMaxClockDrift,
+		unbondingPeriod,
+		maxClockDrift,
 		signer,
 	)
 }
@@ -202,13 +217,19 @@ func (src *PathEnd) MsgRecvPacket(dst *PathEnd, sequence, timeoutHeight, timeout
 	)
 }
 
-// MsgTimeout creates MsgTimeout
-func (src *PathEnd) MsgTimeout(packet chanTypes.Packet, seq uint64, proof chanTypes.PacketResponse, signer sdk.AccAddress) sdk.Msg {
+// MsgTimeout creates MsgTimeout, nextSeqRecv is only checked for ORDERED channels
+func (src *PathEnd) MsgTimeout(dst *PathEnd, sequence, timeoutHeight, timeoutStamp, nextSeqRecv uint64, packetData []byte, proof commitmenttypes.MerkleProof, proofHeight uint64, signer sdk.AccAddress) sdk.Msg {
 	return chanTypes.NewMsgTimeout(
-		packet,
-		seq,
-		proof.Proof,
-		proof.ProofHeight+1,
+		src.NewPacket(
+			dst,
+			sequence,
+			packetData,
+			timeoutHeight,
+			timeoutStamp,
+		),
+		nextSeqRecv,
+		proof,
+		proofHeight+1,
 		signer,
 	)
 }
@@ -1,10 +1,13 @@
 package relayer
 
 import (
+	"context"
 	"encoding/binary"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -24,6 +27,7 @@ import (
 	tmclient "github.com/cosmos/cosmos-sdk/x/ibc/07-tendermint/types"
 	commitmenttypes "github.com/cosmos/cosmos-sdk/x/ibc/23-commitment/types"
 	ibctypes "github.com/cosmos/cosmos-sdk/x/ibc/types"
+	stakingTypes "github.com/cosmos/cosmos-sdk/x/staking/types"
 	abci "github.com/tendermint/tendermint/abci/types"
 	rpcclient "github.com/tendermint/tendermint/rpc/client"
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
@@ -39,7 +43,7 @@ var eventFormat = "{eventType}.{eventAttribute}={value}"
 // TODO: Validate all info coming back from these queries using the verifier
 
 // QueryBalance returns the amount of coins in the relayer account
-func (c *Chain) QueryBalance(keyName string) (sdk.Coins, error) {
+func (c *Chain) QueryBalance(ctx context.Context, keyName string) (sdk.Coins, error) {
 	var (
 		bz    []byte
 		err   error
@@ -61,7 +65,7 @@ func (c *Chain) QueryBalance(keyName string) (sdk.Coins, error) {
 		return nil, qBalErr(addr, err)
 	}
 
-	if bz, _, err = c.QueryWithData(route, bz); err != nil {
+	if bz, _, err = c.querier(ctx).QueryWithData(route, bz); err != nil {
 		return nil, qBalErr(addr, err)
 	}
 
@@ -76,34 +80,52 @@ func qBalErr(acc sdk.AccAddress, err error) error {
 	return fmt.Errorf("query balance for acct %s failed: %w", acc.String(), err)
 }
 
+// QueryUnbondingPeriod returns the unbonding period from the chain's staking params
+func (c *Chain) QueryUnbondingPeriod(ctx context.Context) (time.Duration, error) {
+	var (
+		params stakingTypes.Params
+		route  = fmt.Sprintf("custom/%s/%s", stakingTypes.QuerierRoute, stakingTypes.QueryParameters)
+	)
+
+	bz, _, err := c.querier(ctx).QueryWithData(route, nil)
+	if err != nil {
+		return 0, qUnbondingErr(err)
+	}
+
+	if err = c.Amino.UnmarshalJSON(bz, &params); err != nil {
+		return 0, qUnbondingErr(err)
+	}
+
+	return params.UnbondingTime, nil
+}
+
+func qUnbondingErr(err error) error { return fmt.Errorf("query unbonding period failed: %w", err) }
+
 //////////////////////////////
 //    ICS 02 -> CLIENTS     //
 //////////////////////////////
 
 // QueryConsensusState returns a consensus state for a given chain to be used as a
 // client in another chain, fetches latest height when passed 0 as arg
-func (c *Chain) QueryConsensusState(height int64) (*tmclient.ConsensusState, error) {
+func (c *Chain) QueryConsensusState(ctx context.Context, height int64) (*tmclient.ConsensusState, error) {
 	var (
 		commit     *ctypes.ResultCommit
 		validators *ctypes.ResultValidators
 		err        error
 	)
 
+	commitHeight := &height
 	if height == 0 {
-		commit, err = c.Client.Commit(nil)
-		if err != nil {
-			return nil, qConsStateErr(err)
-		}
-		validators, err = c.Client.Validators(nil, 1, 10000)
-	} else {
-		commit, err = c.Client.Commit(&height)
-		if err != nil {
-			return nil, qConsStateErr(err)
-		}
-		validators, err = c.Client.Validators(nil, 1, 10000)
+		commitHeight = nil
 	}
 
-	if err != nil {
+	if err = rpcCall(ctx, func() (err error) {
+		if commit, err = c.Client.Commit(commitHeight); err != nil {
+			return err
+		}
+		validators, err = c.Client.Validators(nil, 1, 10000)
+		return err
+	}); err != nil {
 		return nil, qConsStateErr(err)
 	}
 
@@ -120,7 +142,7 @@ func qConsStateErr(err error) error { return fmt.Errorf("query cons state failed
 
 // QueryClientConsensusState retrevies the latest consensus state for a client in state at a given height
 // NOTE: dstHeight is the height from dst that is stored on src, it is needed to construct the appropriate store query
-func (c *Chain) QueryClientConsensusState(srcHeight, srcClientConsHeight int64) (clientTypes.ConsensusStateResponse, error) {
+func (c *Chain) QueryClientConsensusState(ctx context.Context, srcHeight, srcClientConsHeight int64) (clientTypes.ConsensusStateResponse, error) {
 	var conStateRes clientTypes.ConsensusStateResponse
 	if !c.PathSet() {
 		return conStateRes, c.ErrPathNotSet()
@@ -133,7 +155,7 @@ func (c *Chain) QueryClientConsensusState(srcHeight, srcClientConsHeight int64)
 		Prove:  true,
 	}
 
-	res, err := c.QueryABCI(req)
+	res, err := c.QueryABCI(ctx, req)
 	if err != nil {
 		return conStateRes, qClntConsStateErr(err)
 	} else if res.Value == nil {
@@ -164,7 +186,7 @@ type chh struct {
 }
 
 // QueryClientConsensusStatePair allows for the querying of multiple client states at the same time
-func QueryClientConsensusStatePair(src, dst *Chain, srcH, dstH, srcClientConsH, dstClientConsH int64) (map[string]clientTypes.ConsensusStateResponse, error) {
+func QueryClientConsensusStatePair(ctx context.Context, src, dst *Chain, srcH, dstH, srcClientConsH, dstClientConsH int64) (map[string]clientTypes.ConsensusStateResponse, error) {
 	hs := &csstates{
 		Map:  make(map[string]clientTypes.ConsensusStateResponse),
 		Errs: []error{},
@@ -180,7 +202,7 @@ func QueryClientConsensusStatePair(src, dst *Chain, srcH, dstH, srcClientConsH,
 	for _, chain := range chps {
 		wg.Add(1)
 		go func(hs *csstates, wg *sync.WaitGroup, chp chh) {
-			conn, err := chp.c.QueryClientConsensusState(chp.h, chp.csh)
+			conn, err := chp.c.QueryClientConsensusState(ctx, chp.h, chp.csh)
 			if err != nil {
 				hs.Lock()
 				hs.Errs = append(hs.Errs, err)
@@ -199,7 +221,7 @@ func QueryClientConsensusStatePair(src, dst *Chain, srcH, dstH, srcClientConsH,
 func qClntConsStateErr(err error) error { return fmt.Errorf("query client cons state failed: %w", err) }
 
 // QueryClientState retrevies the latest consensus state for a client in state at a given height
-func (c *Chain) QueryClientState() (*clientTypes.StateResponse, error) {
+func (c *Chain) QueryClientState(ctx context.Context) (*clientTypes.StateResponse, error) {
 	var conStateRes *clientTypes.StateResponse
 	if !c.PathSet() {
 		return nil, c.ErrPathNotSet()
@@ -211,7 +233,7 @@ func (c *Chain) QueryClientState() (*clientTypes.StateResponse, error) {
 		Prove: true,
 	}
 
-	res, err := c.QueryABCI(req)
+	res, err := c.QueryABCI(ctx, req)
 	if err != nil {
 		return conStateRes, qClntStateErr(err)
 	} else if res.Value == nil {
@@ -240,7 +262,7 @@ type cstates struct {
 }
 
 // QueryClientStatePair returns a pair of connection responses
-func QueryClientStatePair(src, dst *Chain) (map[string]*clientTypes.StateResponse, error) {
+func QueryClientStatePair(ctx context.Context, src, dst *Chain) (map[string]*clientTypes.StateResponse, error) {
 	hs := &cstates{
 		Map:  make(map[string]*clientTypes.StateResponse),
 		Errs: []error{},
@@ -253,7 +275,7 @@ func QueryClientStatePair(src, dst *Chain) (map[string]*clientTypes.StateRespons
 	for _, chain := range chps {
 		wg.Add(1)
 		go func(hs *cstates, wg *sync.WaitGroup, c *Chain) {
-			conn, err := c.QueryClientState()
+			conn, err := c.QueryClientState(ctx)
 			if err != nil {
 				hs.Lock()
 				hs.Errs = append(hs.Errs, err)
@@ -272,7 +294,7 @@ func QueryClientStatePair(src, dst *Chain) (map[string]*clientTypes.StateRespons
 func qClntStateErr(err error) error { return fmt.Errorf("query client state failed: %w", err) }
 
 // QueryClients queries all the clients!
-func (c *Chain) QueryClients(page, limit int) ([]clientExported.ClientState, error) {
+func (c *Chain) QueryClients(ctx context.Context, page, limit int) ([]clientExported.ClientState, error) {
 	var (
 		bz      []byte
 		err     error
@@ -283,7 +305,7 @@ func (c *Chain) QueryClients(page, limit int) ([]clientExported.ClientState, err
 		return nil, qClntsErr(err)
 	}
 
-	if bz, _, err = c.QueryWithData(ibcQuerierRoute(clientTypes.QuerierRoute, clientTypes.QueryAllClients), bz); err != nil {
+	if bz, _, err = c.querier(ctx).QueryWithData(ibcQuerierRoute(clientTypes.QuerierRoute, clientTypes.QueryAllClients), bz); err != nil {
 		return nil, qClntsErr(err)
 	}
 
@@ -301,13 +323,13 @@ func qClntsErr(err error) error { return fmt.Errorf("query clients failed: %w",
 //////////////////////////////
 
 // QueryConnections gets any connections on a chain
-func (c *Chain) QueryConnections(page, limit int) (conns []connTypes.IdentifiedConnectionEnd, err error) {
+func (c *Chain) QueryConnections(ctx context.Context, page, limit int) (conns []connTypes.IdentifiedConnectionEnd, err error) {
 	var bz []byte
 	if bz, err = c.Cdc.MarshalJSON(connTypes.NewQueryAllConnectionsParams(page, limit)); err != nil {
 		return nil, qConnsErr(err)
 	}
 
-	if bz, _, err = c.QueryWithData(ibcQuerierRoute(connTypes.QuerierRoute, connTypes.QueryAllConnections), bz); err != nil {
+	if bz, _, err = c.querier(ctx).QueryWithData(ibcQuerierRoute(connTypes.QuerierRoute, connTypes.QueryAllConnections), bz); err != nil {
 		return nil, qConnsErr(err)
 	}
 
@@ -321,7 +343,7 @@ func (c *Chain) QueryConnections(page, limit int) (conns []connTypes.IdentifiedC
 func qConnsErr(err error) error { return fmt.Errorf("query connections failed: %w", err) }
 
 // QueryConnectionsUsingClient gets any connections that exist between chain and counterparty
-func (c *Chain) QueryConnectionsUsingClient(height int64) (clientConns connTypes.ClientConnectionsResponse, err error) {
+func (c *Chain) QueryConnectionsUsingClient(ctx context.Context, height int64) (clientConns connTypes.ClientConnectionsResponse, err error) {
 	if !c.PathSet() {
 		return clientConns, c.ErrPathNotSet()
 	}
@@ -333,7 +355,7 @@ func (c *Chain) QueryConnectionsUsingClient(height int64) (clientConns connTypes
 		Prove:  true,
 	}
 
-	res, err := c.QueryABCI(req)
+	res, err := c.QueryABCI(ctx, req)
 	if err != nil {
 		return clientConns, qConnsUsingClntsErr(err)
 	}
@@ -353,7 +375,7 @@ func qConnsUsingClntsErr(err error) error {
 }
 
 // QueryConnection returns the remote end of a given connection
-func (c *Chain) QueryConnection(height int64) (connTypes.ConnectionResponse, error) {
+func (c *Chain) QueryConnection(ctx context.Context, height int64) (connTypes.ConnectionResponse, error) {
 	if !c.PathSet() {
 		return connTypes.ConnectionResponse{}, c.ErrPathNotSet()
 	}
@@ -365,7 +387,7 @@ func (c *Chain) QueryConnection(height int64) (connTypes.ConnectionResponse, err
 		Prove:  true,
 	}
 
-	res, err := c.QueryABCI(req)
+	res, err := c.QueryABCI(ctx, req)
 	if err != nil {
 		return connTypes.ConnectionResponse{}, qConnErr(err)
 	} else if res.Value == nil {
@@ -395,7 +417,7 @@ type chpair struct {
 }
 
 // QueryConnectionPair returns a pair of connection responses
-func QueryConnectionPair(src, dst *Chain, srcH, dstH int64) (map[string]connTypes.ConnectionResponse, error) {
+func QueryConnectionPair(ctx context.Context, src, dst *Chain, srcH, dstH int64) (map[string]connTypes.ConnectionResponse, error) {
 	hs := &conns{
 		Map:  make(map[string]connTypes.ConnectionResponse),
 		Errs: []error{},
@@ -411,7 +433,7 @@ func QueryConnectionPair(src, dst *Chain, srcH, dstH int64) (map[string]connType
 	for _, chain := range chps {
 		wg.Add(1)
 		go func(hs *conns, wg *sync.WaitGroup, chp chpair) {
-			conn, err := chp.c.QueryConnection(chp.h)
+			conn, err := chp.c.QueryConnection(ctx, chp.h)
 			if err != nil {
 				hs.Lock()
 				hs.Errs = append(hs.Errs, err)
@@ -442,7 +464,7 @@ var emptyConnRes = connTypes.ConnectionResponse{Connection: connTypes.Identified
 //////////////////////////////
 
 // QueryConnectionChannels queries the channels associated with a connection
-func (c *Chain) QueryConnectionChannels(connectionID string, page, limit int) ([]chanTypes.IdentifiedChannel, error) {
+func (c *Chain) QueryConnectionChannels(ctx context.Context, connectionID string, page, limit int) ([]chanTypes.IdentifiedChannel, error) {
 	var (
 		bz       []byte
 		err      error
@@ -453,7 +475,7 @@ func (c *Chain) QueryConnectionChannels(connectionID string, page, limit int) ([
 		return nil, qChansErr(err)
 	}
 
-	if bz, _, err = c.QueryWithData(ibcQuerierRoute(chanTypes.QuerierRoute, chanTypes.QueryConnectionChannels), bz); err != nil {
+	if bz, _, err = c.querier(ctx).QueryWithData(ibcQuerierRoute(chanTypes.QuerierRoute, chanTypes.QueryConnectionChannels), bz); err != nil {
 		return nil, qChansErr(err)
 	}
 
@@ -465,7 +487,7 @@ func (c *Chain) QueryConnectionChannels(connectionID string, page, limit int) ([
 }
 
 // QueryChannel returns the channel associated with a channelID
-func (c *Chain) QueryChannel(height int64) (chanRes chanTypes.ChannelResponse, err error) {
+func (c *Chain) QueryChannel(ctx context.Context, height int64) (chanRes chanTypes.ChannelResponse, err error) {
 	if !c.PathSet() {
 		return chanRes, c.ErrPathNotSet()
 	}
@@ -477,7 +499,7 @@ func (c *Chain) QueryChannel(height int64) (chanRes chanTypes.ChannelResponse, e
 		Prove:  true,
 	}
 
-	res, err := c.QueryABCI(req)
+	res, err := c.QueryABCI(ctx, req)
 	if err != nil {
 		return chanRes, qChanErr(err)
 	} else if res.Value == nil {
@@ -502,7 +524,7 @@ type chans struct {
 }
 
 // QueryChannelPair returns a pair of channel responses
-func QueryChannelPair(src, dst *Chain, srcH, dstH int64) (map[string]chanTypes.ChannelResponse, error) {
+func QueryChannelPair(ctx context.Context, src, dst *Chain, srcH, dstH int64) (map[string]chanTypes.ChannelResponse, error) {
 	hs := &chans{
 		Map:  make(map[string]chanTypes.ChannelResponse),
 		Errs: []error{},
@@ -518,7 +540,7 @@ func QueryChannelPair(src, dst *Chain, srcH, dstH int64) (map[string]chanTypes.C
 	for _, chain := range chps {
 		wg.Add(1)
 		go func(hs *chans, wg *sync.WaitGroup, chp chpair) {
-			conn, err := chp.c.QueryChannel(chp.h)
+			conn, err := chp.c.QueryChannel(ctx, chp.h)
 			if err != nil {
 				hs.Lock()
 				hs.Errs = append(hs.Errs, err)
@@ -537,7 +559,7 @@ func QueryChannelPair(src, dst *Chain, srcH, dstH int64) (map[string]chanTypes.C
 func qChanErr(err error) error { return fmt.Errorf("query channel failed: %w", err) }
 
 // QueryChannels returns all the channels that are registered on a chain
-func (c *Chain) QueryChannels(page, limit int) ([]chanTypes.IdentifiedChannel, error) {
+func (c *Chain) QueryChannels(ctx context.Context, page, limit int) ([]chanTypes.IdentifiedChannel, error) {
 	var (
 		bz       []byte
 		err      error
@@ -548,7 +570,7 @@ func (c *Chain) QueryChannels(page, limit int) ([]chanTypes.IdentifiedChannel, e
 		return nil, qChansErr(err)
 	}
 
-	if bz, _, err = c.QueryWithData(ibcQuerierRoute(chanTypes.QuerierRoute, chanTypes.QueryAllChannels), bz); err != nil {
+	if bz, _, err = c.querier(ctx).QueryWithData(ibcQuerierRoute(chanTypes.QuerierRoute, chanTypes.QueryAllChannels), bz); err != nil {
 		return nil, qChansErr(err)
 	}
 
@@ -562,30 +584,38 @@ func (c *Chain) QueryChannels(page, limit int) ([]chanTypes.IdentifiedChannel, e
 func qChansErr(err error) error { return fmt.Errorf("query channels failed: %w", err) }
 
 // WaitForNBlocks blocks until the next block on a given chain
-func (c *Chain) WaitForNBlocks(n int64) error {
-	var initial int64
-	h, err := c.Client.Status()
-	if err != nil {
+func (c *Chain) WaitForNBlocks(ctx context.Context, n int64) error {
+	var h *ctypes.ResultStatus
+	status := func() (err error) {
+		h, err = c.Client.Status()
+		return err
+	}
+
+	if err := rpcCall(ctx, status); err != nil {
 		return err
 	}
 	if h.SyncInfo.CatchingUp {
 		return fmt.Errorf("chain catching up")
 	}
-	initial = h.SyncInfo.LatestBlockHeight
+	initial := h.SyncInfo.LatestBlockHeight
 	for {
-		h, err = c.Client.Status()
-		if err != nil {
+		if err := rpcCall(ctx, status); err != nil {
 			return err
 		}
 		if h.SyncInfo.LatestBlockHeight > initial+n {
 			return nil
 		}
-		time.Sleep(10 * time.Millisecond)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(10 * time.Millisecond):
+		}
 	}
 }
 
 // QueryNextSeqRecv returns the next seqRecv for a configured channel
-func (c *Chain) QueryNextSeqRecv(height int64) (recvRes chanTypes.RecvResponse, err error) {
+func (c *Chain) QueryNextSeqRecv(ctx context.Context, height int64) (recvRes chanTypes.RecvResponse, err error) {
 	if !c.PathSet() {
 		return recvRes, c.ErrPathNotSet()
 	}
@@ -597,7 +627,7 @@ func (c *Chain) QueryNextSeqRecv(height int64) (recvRes chanTypes.RecvResponse,
 		Prove:  true,
 	}
 
-	res, err := c.QueryABCI(req)
+	res, err := c.QueryABCI(ctx, req)
 	if err != nil {
 		return recvRes, err
 	} else if res.Value == nil {
@@ -656,30 +686,135 @@ func newRlySeq(start, end uint64) []uint64 {
 }
 
 // UnrelayedSequences returns the unrelayed sequence numbers between two chains
-func UnrelayedSequences(src, dst *Chain, sh *SyncHeaders) (*RelaySequences, error) {
-	seqP, err := QueryNextSeqPairs(src, dst, sh)
+func UnrelayedSequences(ctx context.Context, src, dst *Chain, sh *SyncHeaders) (*RelaySequences, error) {
+	seqP, err := QueryNextSeqPairs(ctx, src, dst, sh)
 	if err != nil {
 		return nil, err
 	}
 	return seqP.ToRelay(), err
 }
 
+// UnrelayedSequencesUnordered returns the unrelayed sequence numbers between two chains
+// on an unordered channel. A packet is unrelayed if its commitment still exists on the
+// sending chain and there is no acknowledgement for it on the receiving chain.
+func UnrelayedSequencesUnordered(ctx context.Context, src, dst *Chain, sh *SyncHeaders) (*RelaySequences, error) {
+	var (
+		rs = &RelaySequences{Src: []uint64{}, Dst: []uint64{}}
+		wg sync.WaitGroup
+		mu sync.Mutex
+		es = errs{}
+	)
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		seqs, _, err := packetSequencesByAck(ctx, src, dst, sh)
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			es = append(es, err)
+		}
+		rs.Src = seqs
+	}()
+	go func() {
+		defer wg.Done()
+		seqs, _, err := packetSequencesByAck(ctx, dst, src, sh)
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			es = append(es, err)
+		}
+		rs.Dst = seqs
+	}()
+	wg.Wait()
+
+	if err := es.err(); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+// UnrelayedAcknowledgements returns the sequence numbers of the packets that have been
+// received and acknowledged on each chain, but whose acknowledgement has not yet been
+// relayed back to the sending chain. Src holds the acks written on src to be relayed
+// to dst and Dst holds the acks written on dst to be relayed to src.
+func UnrelayedAcknowledgements(ctx context.Context, src, dst *Chain, sh *SyncHeaders) (*RelaySequences, error) {
+	var (
+		rs = &RelaySequences{Src: []uint64{}, Dst: []uint64{}}
+		wg sync.WaitGroup
+		mu sync.Mutex
+		es = errs{}
+	)
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_, seqs, err := packetSequencesByAck(ctx, dst, src, sh)
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			es = append(es, err)
+		}
+		rs.Src = seqs
+	}()
+	go func() {
+		defer wg.Done()
+		_, seqs, err := packetSequencesByAck(ctx, src, dst, sh)
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			es = append(es, err)
+		}
+		rs.Dst = seqs
+	}()
+	wg.Wait()
+
+	if err := es.err(); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+// packetSequencesByAck returns the sequences of the packets committed on src split by whether
+// dst has written an acknowledgement for them. A commitment is removed once the ack or timeout
+// has been relayed back to src, so every sequence returned still needs relaying.
+func packetSequencesByAck(ctx context.Context, src, dst *Chain, sh *SyncHeaders) (unreceived, unacked []uint64, err error) {
+	commits, err := src.QueryPacketCommitments(ctx, int64(sh.GetHeight(src.ChainID)))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	unreceived, unacked = []uint64{}, []uint64{}
+	for _, seq := range commits {
+		ack, err := dst.QueryPacketAck(ctx, int64(sh.GetHeight(dst.ChainID)), int64(seq))
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(ack.Data) == 0 {
+			unreceived = append(unreceived, seq)
+		} else {
+			unacked = append(unacked, seq)
+		}
+	}
+	return unreceived, unacked, nil
+}
+
 // QueryNextSeqPairs returns a pair of chain's next sequences for the configured channel
-func QueryNextSeqPairs(src, dst *Chain, sh *SyncHeaders) (*SeqPairs, error) {
+func QueryNextSeqPairs(ctx context.Context, src, dst *Chain, sh *SyncHeaders) (*SeqPairs, error) {
 	sps := &SeqPairs{Src: &SeqPair{}, Dst: &SeqPair{}, errs: errs{}}
 	var wg sync.WaitGroup
 	wg.Add(4)
-	go src.queryNextSendWG(sps, int64(sh.GetHeight(src.ChainID)), &wg, true)
-	go src.queryNextRecvWG(sps, int64(sh.GetHeight(src.ChainID)), &wg, true)
-	go dst.queryNextSendWG(sps, int64(sh.GetHeight(dst.ChainID)), &wg, false)
-	go dst.queryNextRecvWG(sps, int64(sh.GetHeight(dst.ChainID)), &wg, false)
+	go src.queryNextSendWG(ctx, sps, int64(sh.GetHeight(src.ChainID)), &wg, true)
+	go src.queryNextRecvWG(ctx, sps, int64(sh.GetHeight(src.ChainID)), &wg, true)
+	go dst.queryNextSendWG(ctx, sps, int64(sh.GetHeight(dst.ChainID)), &wg, false)
+	go dst.queryNextRecvWG(ctx, sps, int64(sh.GetHeight(dst.ChainID)), &wg, false)
 	wg.Wait()
 	return sps, sps.errs.err()
 }
 
-func (c *Chain) queryNextSendWG(sps *SeqPairs, h int64, wg *sync.WaitGroup, src bool) {
+func (c *Chain) queryNextSendWG(ctx context.Context, sps *SeqPairs, h int64, wg *sync.WaitGroup, src bool) {
 	defer wg.Done()
-	seqSend, err := c.QueryNextSeqSend(h)
+	seqSend, err := c.QueryNextSeqSend(ctx, h)
 	sps.Lock()
 	defer sps.Unlock()
 	if err != nil {
@@ -692,9 +827,9 @@ func (c *Chain) queryNextSendWG(sps *SeqPairs, h int64, wg *sync.WaitGroup, src
 	}
 }
 
-func (c *Chain) queryNextRecvWG(sps *SeqPairs, h int64, wg *sync.WaitGroup, src bool) {
+func (c *Chain) queryNextRecvWG(ctx context.Context, sps *SeqPairs, h int64, wg *sync.WaitGroup, src bool) {
 	defer wg.Done()
-	seqRecv, err := c.QueryNextSeqRecv(h)
+	seqRecv, err := c.QueryNextSeqRecv(ctx, h)
 	sps.Lock()
 	defer sps.Unlock()
 	if err != nil {
@@ -708,7 +843,7 @@ func (c *Chain) queryNextRecvWG(sps *SeqPairs, h int64, wg *sync.WaitGroup, src
 }
 
 // QueryNextSeqSend returns the next seqSend for a configured channel
-func (c *Chain) QueryNextSeqSend(height int64) (uint64, error) {
+func (c *Chain) QueryNextSeqSend(ctx context.Context, height int64) (uint64, error) {
 	if !c.PathSet() {
 		return 0, c.ErrPathNotSet()
 	}
@@ -720,7 +855,7 @@ func (c *Chain) QueryNextSeqSend(height int64) (uint64, error) {
 		Prove:  true,
 	}
 
-	res, err := c.QueryABCI(req)
+	res, err := c.QueryABCI(ctx, req)
 	if err != nil {
 		return 0, err
 	} else if res.Value == nil {
@@ -732,7 +867,7 @@ func (c *Chain) QueryNextSeqSend(height int64) (uint64, error) {
 }
 
 // QueryPacketCommitment returns the packet commitment proof at a given height
-func (c *Chain) QueryPacketCommitment(height, seq int64) (comRes CommitmentResponse, err error) {
+func (c *Chain) QueryPacketCommitment(ctx context.Context, height, seq int64) (comRes CommitmentResponse, err error) {
 	if !c.PathSet() {
 		return comRes, c.ErrPathNotSet()
 	}
@@ -744,7 +879,7 @@ func (c *Chain) QueryPacketCommitment(height, seq int64) (comRes CommitmentRespo
 		Prove:  true,
 	}
 
-	res, err := c.QueryABCI(req)
+	res, err := c.QueryABCI(ctx, req)
 	if err != nil {
 		return comRes, qPacketCommitmentErr(err)
 	} else if res.Value == nil {
@@ -765,6 +900,44 @@ func (c *Chain) QueryPacketCommitment(height, seq int64) (comRes CommitmentRespo
 	}, nil
 }
 
+// QueryPacketCommitments returns the sequences of all the packet commitments
+// stored for the configured channel at a given height, in ascending order
+func (c *Chain) QueryPacketCommitments(ctx context.Context, height int64) ([]uint64, error) {
+	if !c.PathSet() {
+		return nil, c.ErrPathNotSet()
+	}
+
+	prefix := fmt.Sprintf("%s/ports/%s/channels/%s/packets/",
+		ibctypes.KeyPacketCommitmentPrefix, c.PathEnd.PortID, c.PathEnd.ChannelID)
+
+	res, err := c.QueryABCI(ctx, abci.RequestQuery{
+		Path:   "store/ibc/subspace",
+		Data:   []byte(prefix),
+		Height: height,
+	})
+	if err != nil {
+		return nil, qPacketCommitmentErr(err)
+	}
+
+	var kvs []sdk.KVPair
+	if len(res.Value) > 0 {
+		if err = c.Amino.UnmarshalBinaryBare(res.Value, &kvs); err != nil {
+			return nil, qPacketCommitmentErr(err)
+		}
+	}
+
+	seqs := make([]uint64, 0, len(kvs))
+	for _, kv := range kvs {
+		seq, err := strconv.ParseUint(strings.TrimPrefix(string(kv.Key), prefix), 10, 64)
+		if err != nil {
+			return nil, qPacketCommitmentErr(err)
+		}
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+	return seqs, nil
+}
+
 func qPacketCommitmentErr(err error) error {
 	return fmt.Errorf("query packet commitment failed: %w", err)
 }
@@ -779,7 +952,7 @@ type CommitmentResponse struct {
 }
 
 // QueryPacketAck returns the packet commitment proof at a given height
-func (c *Chain) QueryPacketAck(height, seq int64) (comRes CommitmentResponse, err error) {
+func (c *Chain) QueryPacketAck(ctx context.Context, height, seq int64) (comRes CommitmentResponse, err error) {
 	if !c.PathSet() {
 		return comRes, c.ErrPathNotSet()
 	}
@@ -791,7 +964,7 @@ func (c *Chain) QueryPacketAck(height, seq int64) (comRes CommitmentResponse, er
 		Prove:  true,
 	}
 
-	res, err := c.QueryABCI(req)
+	res, err := c.QueryABCI(ctx, req)
 	if err != nil {
 		return comRes, qPacketAckErr(err)
 	} else if res.Value == nil {
@@ -811,6 +984,41 @@ func (c *Chain) QueryPacketAck(height, seq int64) (comRes CommitmentResponse, er
 	}, nil
 }
 
+// QueryPacketAckAbsence returns the proof that there is no packet acknowledgement
+// for the given sequence at a given height, which proves an unordered packet was not received
+func (c *Chain) QueryPacketAckAbsence(ctx context.Context, height, seq int64) (comRes CommitmentResponse, err error) {
+	if !c.PathSet() {
+		return comRes, c.ErrPathNotSet()
+	}
+
+	req := abci.RequestQuery{
+		Path:   "store/ibc/key",
+		Data:   ibctypes.KeyPacketAcknowledgement(c.PathEnd.PortID, c.PathEnd.ChannelID, uint64(seq)),
+		Height: height,
+		Prove:  true,
+	}
+
+	res, err := c.QueryABCI(ctx, req)
+	if err != nil {
+		return comRes, qPacketAckErr(err)
+	} else if res.Value != nil {
+		return comRes, qPacketAckErr(fmt.Errorf("seq(%d): %w", seq, errPacketReceived))
+	}
+
+	return CommitmentResponse{
+		Proof: commitmenttypes.MerkleProof{Proof: res.Proof},
+		ProofPath: commitmenttypes.NewMerklePath(
+			strings.Split(
+				string(ibctypes.KeyPacketAcknowledgement(c.PathEnd.PortID, c.PathEnd.ChannelID, uint64(seq))),
+				"/",
+			),
+		),
+		ProofHeight: uint64(res.Height),
+	}, nil
+}
+
+var errPacketReceived = errors.New("packet has been received")
+
 func qPacketAckErr(err error) error {
 	return fmt.Errorf("query packet acknowledgement failed: %w", err)
 }
@@ -819,6 +1027,7 @@ func qPacketAckErr(err error) error {
 type PathStatus struct {
 	Chains       map[string]*ChainStatus `json:"chains" yaml:"chains"`
 	UnrelayedSeq *RelaySequences         `json:"unrelayed-seq" yaml:"unrelayed-seq"`
+	UnrelayedAck *RelaySequences         `json:"unrelayed-ack" yaml:"unrelayed-ack"`
 	src          string
 	dst          string
 }
@@ -853,7 +1062,7 @@ type ChannelStatus struct {
 }
 
 // QueryPathStatus takes both ends of a path and queries all the data about the link
-func QueryPathStatus(src, dst *Chain, path *Path) (stat *PathStatus, err error) {
+func QueryPathStatus(ctx context.Context, src, dst *Chain, path *Path) (stat *PathStatus, err error) {
 	stat = &PathStatus{
 		Chains: map[string]*ChainStatus{
 			src.ChainID: {
@@ -872,6 +1081,7 @@ func QueryPathStatus(src, dst *Chain, path *Path) (stat *PathStatus, err error)
 			},
 		},
 		UnrelayedSeq: &RelaySequences{},
+		UnrelayedAck: &RelaySequences{},
 		src:          src.ChainID,
 		dst:          dst.ChainID,
 	}
@@ -883,7 +1093,7 @@ func QueryPathStatus(src, dst *Chain, path *Path) (stat *PathStatus, err error)
 		return
 	}
 
-	sh, err := NewSyncHeaders(src, dst)
+	sh, err := NewSyncHeaders(ctx, src, dst)
 	if err != nil {
 		return
 	}
@@ -894,35 +1104,35 @@ func QueryPathStatus(src, dst *Chain, path *Path) (stat *PathStatus, err error)
 	stat.Chains[dst.ChainID].Height = int64(sh.GetHeight(dst.ChainID))
 	stat.Chains[dst.ChainID].Reachable = true
 
-	srcCs, err := src.QueryClientState()
+	srcCs, err := src.QueryClientState(ctx)
 	if err != nil {
 		return
 	}
 	stat.Chains[src.ChainID].Client.ID = srcCs.ClientState.GetID()
 	stat.Chains[src.ChainID].Client.Height = srcCs.ClientState.GetLatestHeight()
 
-	dstCs, err := dst.QueryClientState()
+	dstCs, err := dst.QueryClientState(ctx)
 	if err != nil {
 		return
 	}
 	stat.Chains[dst.ChainID].Client.ID = dstCs.ClientState.GetID()
 	stat.Chains[dst.ChainID].Client.Height = dstCs.ClientState.GetLatestHeight()
 
-	srcConn, err := src.QueryConnection(int64(sh.GetHeight(src.ChainID)))
+	srcConn, err := src.QueryConnection(ctx, int64(sh.GetHeight(src.ChainID)))
 	if err != nil {
 		return
 	}
 	stat.Chains[src.ChainID].Connection.ID = srcConn.Connection.Identifier
 	stat.Chains[src.ChainID].Connection.State = srcConn.Connection.Connection.GetState().String()
 
-	dstConn, err := dst.QueryConnection(int64(sh.GetHeight(dst.ChainID)))
+	dstConn, err := dst.QueryConnection(ctx, int64(sh.GetHeight(dst.ChainID)))
 	if err != nil {
 		return
 	}
 	stat.Chains[dst.ChainID].Connection.ID = dstConn.Connection.Identifier
 	stat.Chains[dst.ChainID].Connection.State = dstConn.Connection.Connection.GetState().String()
 
-	srcChan, err := src.QueryChannel(int64(sh.GetHeight(src.ChainID)))
+	srcChan, err := src.QueryChannel(ctx, int64(sh.GetHeight(src.ChainID)))
 	if err != nil {
 		return
 	}
@@ -931,7 +1141,7 @@ func QueryPathStatus(src, dst *Chain, path *Path) (stat *PathStatus, err error)
 	stat.Chains[src.ChainID].Channel.State = srcChan.Channel.Channel.GetState().String()
 	stat.Chains[src.ChainID].Channel.Order = srcChan.Channel.Channel.GetOrdering().String()
 
-	dstChan, err := dst.QueryChannel(int64(sh.GetHeight(dst.ChainID)))
+	dstChan, err := dst.QueryChannel(ctx, int64(sh.GetHeight(dst.ChainID)))
 	if err != nil {
 		return
 	}
@@ -940,29 +1150,43 @@ func QueryPathStatus(src, dst *Chain, path *Path) (stat *PathStatus, err error)
 	stat.Chains[dst.ChainID].Channel.State = dstChan.Channel.Channel.GetState().String()
 	stat.Chains[dst.ChainID].Channel.Order = dstChan.Channel.Channel.GetOrdering().String()
 
-	unrelayed, err := UnrelayedSequences(src, dst, sh)
+	var unrelayed *RelaySequences
+	if path.Ordered() {
+		unrelayed, err = UnrelayedSequences(ctx, src, dst, sh)
+	} else {
+		unrelayed, err = UnrelayedSequencesUnordered(ctx, src, dst, sh)
+	}
 	if err != nil {
 		return
 	}
 	stat.UnrelayedSeq = unrelayed
+
+	unrelayedAcks, err := UnrelayedAcknowledgements(ctx, src, dst, sh)
+	if err != nil {
+		return
+	}
+	stat.UnrelayedAck = unrelayedAcks
 	return
 }
 
 // QueryTx takes a transaction hash and returns the transaction
-func (c *Chain) QueryTx(hashHex string) (sdk.TxResponse, error) {
+func (c *Chain) QueryTx(ctx context.Context, hashHex string) (sdk.TxResponse, error) {
 	hash, err := hex.DecodeString(hashHex)
 	if err != nil {
 		return sdk.TxResponse{}, err
 	}
 
-	resTx, err := c.Client.Tx(hash, true)
-	if err != nil {
+	var resTx *ctypes.ResultTx
+	if err = rpcCall(ctx, func() (err error) {
+		resTx, err = c.Client.Tx(hash, true)
+		return err
+	}); err != nil {
 		return sdk.TxResponse{}, err
 	}
 
 	// TODO: validate data coming back with local lite client
 
-	resBlocks, err := c.queryBlocksForTxResults([]*ctypes.ResultTx{resTx})
+	resBlocks, err := c.queryBlocksForTxResults(ctx, []*ctypes.ResultTx{resTx})
 	if err != nil {
 		return sdk.TxResponse{}, err
 	}
@@ -976,7 +1200,7 @@ func (c *Chain) QueryTx(hashHex string) (sdk.TxResponse, error) {
 }
 
 // QueryTxs returns an array of transactions given a tag
-func (c *Chain) QueryTxs(height uint64, page, limit int, events []string) (*sdk.SearchTxsResult, error) {
+func (c *Chain) QueryTxs(ctx context.Context, height uint64, page, limit int, events []string) (*sdk.SearchTxsResult, error) {
 	if len(events) == 0 {
 		return nil, errors.New("must declare at least one event to search")
 	}
@@ -989,8 +1213,11 @@ func (c *Chain) QueryTxs(height uint64, page, limit int, events []string) (*sdk.
 		return nil, errors.New("limit must greater than 0")
 	}
 
-	resTxs, err := c.Client.TxSearch(strings.Join(events, " AND "), true, page, limit, "")
-	if err != nil {
+	var resTxs *ctypes.ResultTxSearch
+	if err := rpcCall(ctx, func() (err error) {
+		resTxs, err = c.Client.TxSearch(strings.Join(events, " AND "), true, page, limit, "")
+		return err
+	}); err != nil {
 		return nil, err
 	}
 
@@ -1001,7 +1228,7 @@ func (c *Chain) QueryTxs(height uint64, page, limit int, events []string) (*sdk.
 	// 	}
 	// }
 
-	resBlocks, err := c.queryBlocksForTxResults(resTxs.Txs)
+	resBlocks, err := c.queryBlocksForTxResults(ctx, resTxs.Txs)
 	if err != nil {
 		return nil, err
 	}
@@ -1017,20 +1244,23 @@ func (c *Chain) QueryTxs(height uint64, page, limit int, events []string) (*sdk.
 
 // QueryABCI is an affordance for querying the ABCI server associated with a chain
 // Similar to cliCtx.QueryABCI
-func (c *Chain) QueryABCI(req abci.RequestQuery) (res abci.ResponseQuery, err error) {
+func (c *Chain) QueryABCI(ctx context.Context, req abci.RequestQuery) (res abci.ResponseQuery, err error) {
 	opts := rpcclient.ABCIQueryOptions{
 		Height: req.GetHeight(),
 		Prove:  req.Prove,
 	}
 
-	result, err := c.Client.ABCIQueryWithOptions(req.Path, req.Data, opts)
-	if err != nil {
+	var result *ctypes.ResultABCIQuery
+	if err = rpcCall(ctx, func() (err error) {
+		result, err = c.Client.ABCIQueryWithOptions(req.Path, req.Data, opts)
+		return err
+	}); err != nil {
 		// retry queries on EOF
-		if strings.Contains(err.Error(), "EOF") {
+		if strings.Contains(err.Error(), "EOF") && ctx.Err() == nil {
 			if c.debug {
 				c.Error(err)
 			}
-			return c.QueryABCI(req)
+			return c.QueryABCI(ctx, req)
 		}
 		return res, err
 	}
@@ -1051,23 +1281,42 @@ func (c *Chain) QueryABCI(req abci.RequestQuery) (res abci.ResponseQuery, err er
 	return result.Response, nil
 }
 
-// QueryWithData satisfies auth.NodeQuerier interface and used for fetching account details
+// QueryWithData satisfies auth.NodeQuerier interface and used for fetching account details,
+// use querier to make the queries with a context
 func (c *Chain) QueryWithData(p string, d []byte) (byt []byte, i int64, err error) {
-	var res abci.ResponseQuery
-	if res, err = c.QueryABCI(abci.RequestQuery{Path: p, Height: 0, Data: d}); err != nil {
-		return byt, i, err
-	}
+	return c.querier(context.Background()).QueryWithData(p, d)
+}
+
+// nodeQuerier is a func that satisfies the auth.NodeQuerier interface
+type nodeQuerier func(p string, d []byte) ([]byte, int64, error)
 
-	return res.Value, res.Height, nil
+// QueryWithData implements auth.NodeQuerier
+func (q nodeQuerier) QueryWithData(p string, d []byte) ([]byte, int64, error) {
+	return q(p, d)
+}
+
+// querier returns a nodeQuerier that makes its queries with ctx
+func (c *Chain) querier(ctx context.Context) nodeQuerier {
+	return func(p string, d []byte) (byt []byte, i int64, err error) {
+		var res abci.ResponseQuery
+		if res, err = c.QueryABCI(ctx, abci.RequestQuery{Path: p, Height: 0, Data: d}); err != nil {
+			return byt, i, err
+		}
+
+		return res.Value, res.Height, nil
+	}
 }
 
 // QueryLatestHeight queries the chain for the latest height and returns it
-func (c *Chain) QueryLatestHeight() (int64, error) {
-	res, err := c.Client.Status()
-	if err != nil {
+func (c *Chain) QueryLatestHeight(ctx context.Context) (int64, error) {
+	var res *ctypes.ResultStatus
+	if err := rpcCall(ctx, func() (err error) {
+		res, err = c.Client.Status()
+		return err
+	}); err != nil {
 		return -1, err
 	} else if res.SyncInfo.CatchingUp {
-		return -1, fmt.Errorf("node at %s running chain %s not caught up", c.RPCAddr, c.ChainID)
+		return -1, fmt.Errorf("node at %s running chain %s not caught up", c.rpc.Remote(), c.ChainID)
 	}
 
 	return res.SyncInfo.LatestBlockHeight, nil
@@ -1090,13 +1339,13 @@ func (e errs) err() error {
 }
 
 // QueryLatestHeights returns the heights of multiple chains at once
-func QueryLatestHeights(chains ...*Chain) (map[string]int64, error) {
+func QueryLatestHeights(ctx context.Context, chains ...*Chain) (map[string]int64, error) {
 	hs := &heights{Map: make(map[string]int64), Errs: []error{}}
 	var wg sync.WaitGroup
 	for _, chain := range chains {
 		wg.Add(1)
 		go func(hs *heights, wg *sync.WaitGroup, chain *Chain) {
-			height, err := chain.QueryLatestHeight()
+			height, err := chain.QueryLatestHeight(ctx)
 
 			if err != nil {
 				hs.Lock()
@@ -1114,30 +1363,34 @@ func QueryLatestHeights(chains ...*Chain) (map[string]int64, error) {
 }
 
 // QueryLatestHeader returns the latest header from the chain
-func (c *Chain) QueryLatestHeader() (out *tmclient.Header, err error) {
+func (c *Chain) QueryLatestHeader(ctx context.Context) (out *tmclient.Header, err error) {
 	var h int64
-	if h, err = c.QueryLatestHeight(); err != nil {
+	if h, err = c.QueryLatestHeight(ctx); err != nil {
 		return nil, err
 	}
-	if out, err = c.QueryHeaderAtHeight(h); err != nil {
+	if out, err = c.QueryHeaderAtHeight(ctx, h); err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
 // QueryHeaderAtHeight returns the header at a given height
-func (c *Chain) QueryHeaderAtHeight(height int64) (*tmclient.Header, error) {
+func (c *Chain) QueryHeaderAtHeight(ctx context.Context, height int64) (*tmclient.Header, error) {
 	if height <= 0 {
 		return nil, fmt.Errorf("must pass in valid height, %d not valid", height)
 	}
 
-	res, err := c.Client.Commit(&height)
-	if err != nil {
-		return nil, err
-	}
-
-	val, err := c.Client.Validators(&height, 0, 10000)
-	if err != nil {
+	var (
+		res *ctypes.ResultCommit
+		val *ctypes.ResultValidators
+	)
+	if err := rpcCall(ctx, func() (err error) {
+		if res, err = c.Client.Commit(&height); err != nil {
+			return err
+		}
+		val, err = c.Client.Validators(&height, 0, 10000)
+		return err
+	}); err != nil {
 		return nil, err
 	}
 
@@ -1170,12 +1423,15 @@ func isQueryStoreWithProof(path string) bool {
 }
 
 // queryBlocksForTxResults returns a map[blockHeight]txResult
-func (c *Chain) queryBlocksForTxResults(resTxs []*ctypes.ResultTx) (map[int64]*ctypes.ResultBlock, error) {
+func (c *Chain) queryBlocksForTxResults(ctx context.Context, resTxs []*ctypes.ResultTx) (map[int64]*ctypes.ResultBlock, error) {
 	resBlocks := make(map[int64]*ctypes.ResultBlock)
 	for _, resTx := range resTxs {
 		if _, ok := resBlocks[resTx.Height]; !ok {
-			resBlock, err := c.Client.Block(&resTx.Height)
-			if err != nil {
+			var resBlock *ctypes.ResultBlock
+			if err := rpcCall(ctx, func() (err error) {
+				resBlock, err = c.Client.Block(&resTx.Height)
+				return err
+			}); err != nil {
 				return nil, err
 			}
 			resBlocks[resTx.Height] = resBlock
@@ -0,0 +1,122 @@
+package relayer
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// defaultReconcileInterval is used for paths that don't set a reconcile-interval
+var defaultReconcileInterval = time.Minute * 5
+
+// reconciler periodically queries for unrelayed packets and acknowledgements and relays
+// the ones the event listener missed. Only sequences that were also unrelayed on the
+// previous pass are relayed, so packets that are still being relayed from events aren't
+// relayed twice and the counts only include the ones that were actually missed.
+type reconciler struct {
+	src, dst *Chain
+	sh       *SyncHeaders
+	strategy Strategy
+	ordered  bool
+
+	// handshakes completes the handshakes whose events were missed
+	handshakes *handshakeRelayer
+
+	// the sequences that were unrelayed on the previous pass
+	lastPackets, lastAcks *RelaySequences
+
+	// the total number of packets and acks relayed by reconciling
+	packets, acks int
+}
+
+// run reconciles the path every interval until ctx is done
+func (r *reconciler) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.reconcile(ctx); err != nil {
+				r.src.Error(fmt.Errorf("reconcile failed: %w", err))
+			}
+		}
+	}
+}
+
+// reconcile relays the packets and acks that have been unrelayed for two passes in a row
+func (r *reconciler) reconcile(ctx context.Context) error {
+	if err := r.sh.Update(ctx, r.src); err != nil {
+		return err
+	}
+	if err := r.sh.Update(ctx, r.dst); err != nil {
+		return err
+	}
+
+	r.handshakes.converge(ctx)
+
+	sp, err := UnrelayedSequencesForStrategy(ctx, r.src, r.dst, r.sh, r.strategy, r.ordered)
+	if err != nil {
+		return err
+	}
+	missedPackets := intersectSequences(r.lastPackets, sp)
+	r.lastPackets = sp
+
+	ap, err := r.strategy.UnrelayedAcknowledgements(ctx, r.src, r.dst, r.sh)
+	if err != nil {
+		return err
+	}
+	missedAcks := intersectSequences(r.lastAcks, ap)
+	r.lastAcks = ap
+
+	if n := missedPackets.count(); n > 0 {
+		r.packets += n
+		r.src.Log(fmt.Sprintf("- reconcile found %d packets missed by events between [%s]port{%s} and [%s]port{%s}, %d in total",
+			n, r.src.ChainID, r.src.PathEnd.PortID, r.dst.ChainID, r.dst.PathEnd.PortID, r.packets))
+		if err = relaySequences(ctx, r.src, r.dst, missedPackets, r.sh, r.strategy, r.ordered); err != nil {
+			return err
+		}
+	}
+
+	if n := missedAcks.count(); n > 0 {
+		r.acks += n
+		r.src.Log(fmt.Sprintf("- reconcile found %d acknowledgements missed by events between [%s]port{%s} and [%s]port{%s}, %d in total",
+			n, r.src.ChainID, r.src.PathEnd.PortID, r.dst.ChainID, r.dst.PathEnd.PortID, r.acks))
+		if err = r.strategy.RelayAcknowledgements(ctx, r.src, r.dst, missedAcks, r.sh); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// count returns the number of sequences on both chains
+func (rs *RelaySequences) count() int {
+	return len(rs.Src) + len(rs.Dst)
+}
+
+// intersectSequences returns the sequences that are in both prev and cur, the order of cur is kept
+func intersectSequences(prev, cur *RelaySequences) *RelaySequences {
+	out := &RelaySequences{Src: []uint64{}, Dst: []uint64{}}
+	if prev == nil {
+		return out
+	}
+	out.Src = intersect(prev.Src, cur.Src)
+	out.Dst = intersect(prev.Dst, cur.Dst)
+	return out
+}
+
+func intersect(prev, cur []uint64) []uint64 {
+	seen := make(map[uint64]bool, len(prev))
+	for _, seq := range prev {
+		seen[seq] = true
+	}
+	out := []uint64{}
+	for _, seq := range cur {
+		if seen[seq] {
+			out = append(out, seq)
+		}
+	}
+	return out
+}
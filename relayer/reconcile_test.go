@@ -0,0 +1,45 @@
+package relayer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntersectSequences(t *testing.T) {
+	cases := []struct {
+		name      string
+		prev, cur *RelaySequences
+		expected  *RelaySequences
+	}{
+		{"first pass", nil,
+			&RelaySequences{Src: []uint64{1, 2}, Dst: []uint64{3}},
+			&RelaySequences{Src: []uint64{}, Dst: []uint64{}}},
+		{"nothing unrelayed", &RelaySequences{Src: []uint64{}, Dst: []uint64{}},
+			&RelaySequences{Src: []uint64{}, Dst: []uint64{}},
+			&RelaySequences{Src: []uint64{}, Dst: []uint64{}}},
+		{"unrelayed on both passes", &RelaySequences{Src: []uint64{1, 2}, Dst: []uint64{5}},
+			&RelaySequences{Src: []uint64{1, 2}, Dst: []uint64{5}},
+			&RelaySequences{Src: []uint64{1, 2}, Dst: []uint64{5}}},
+		{"relayed since the last pass", &RelaySequences{Src: []uint64{1, 2, 3}, Dst: []uint64{5, 6}},
+			&RelaySequences{Src: []uint64{3}, Dst: []uint64{}},
+			&RelaySequences{Src: []uint64{3}, Dst: []uint64{}}},
+		{"new since the last pass", &RelaySequences{Src: []uint64{1}, Dst: []uint64{}},
+			&RelaySequences{Src: []uint64{1, 2}, Dst: []uint64{7}},
+			&RelaySequences{Src: []uint64{1}, Dst: []uint64{}}},
+		{"keeps the order of the current pass", &RelaySequences{Src: []uint64{4, 2, 3}, Dst: []uint64{}},
+			&RelaySequences{Src: []uint64{2, 3, 4}, Dst: []uint64{}},
+			&RelaySequences{Src: []uint64{2, 3, 4}, Dst: []uint64{}}},
+		{"sides are intersected separately", &RelaySequences{Src: []uint64{1}, Dst: []uint64{2}},
+			&RelaySequences{Src: []uint64{2}, Dst: []uint64{1}},
+			&RelaySequences{Src: []uint64{}, Dst: []uint64{}}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			out := intersectSequences(tc.prev, tc.cur)
+			require.Equal(t, tc.expected, out)
+			require.Equal(t, len(tc.expected.Src)+len(tc.expected.Dst), out.count())
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package relayer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tmclient "github.com/cosmos/cosmos-sdk/x/ibc/07-tendermint/types"
+)
+
+// PathRecovery is the plan for recovering a path whose clients can't be used anymore. A new
+// client is created in place of each unusable one, and as a connection can't change its
+// client, the path gets a new connection and channel on both ends.
+type PathRecovery struct {
+	// Old and New are the path before and after recovery
+	Old, New *Path
+
+	// SrcClient and DstClient explain why the client on each end can't be used, they are empty if it can
+	SrcClient string
+	DstClient string
+
+	// Packets and Acks are the ones left on the old channel, see UnrelayedSequences and
+	// UnrelayedAcknowledgements, they are nil if they couldn't be queried
+	Packets *RelaySequences
+	Acks    *RelaySequences
+
+	// OldName is the name the old path is kept under in the config so that what is left on the
+	// old channel can be relayed, it is empty if the old path isn't kept
+	OldName string
+}
+
+// clientUnusable returns why the client on c can't be used to verify its counterparty anymore,
+// or an empty string if it can. A client is unusable once it is frozen or has expired.
+func (c *Chain) clientUnusable(ctx context.Context) (string, error) {
+	csRes, err := c.QueryClientState(ctx)
+	switch {
+	case err != nil:
+		return "", err
+	case csRes == nil:
+		return "", fmt.Errorf("client %s not found on %s, create it with 'rly tx link'", c.PathEnd.ClientID, c.ChainID)
+	}
+
+	cs, ok := csRes.ClientState.(tmclient.ClientState)
+	if !ok {
+		return "", fmt.Errorf("client %s on %s is not a tendermint client", c.PathEnd.ClientID, c.ChainID)
+	}
+
+	if cs.IsFrozen() {
+		return fmt.Sprintf("was frozen for misbehaviour at height %d", cs.FrozenHeight), nil
+	}
+	if elapsed := time.Since(cs.GetLatestTimestamp()); elapsed >= cs.TrustingPeriod {
+		return fmt.Sprintf("expired %s ago, its trusting period is %s",
+			(elapsed - cs.TrustingPeriod).Round(time.Second), cs.TrustingPeriod), nil
+	}
+	return "", nil
+}
+
+// RecoverPath checks the clients on the path between src and dst. If either of them is frozen or
+// has expired, it returns the plan for recovering the path with new identifiers, otherwise it
+// returns nil. The packets left on the old channel are queried so that they can be reported.
+func RecoverPath(ctx context.Context, src, dst *Chain, path *Path) (*PathRecovery, error) {
+	srcClient, err := src.clientUnusable(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dstClient, err := dst.clientUnusable(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if srcClient == "" && dstClient == "" {
+		return nil, nil
+	}
+
+	srcEnd, dstEnd := *path.Src, *path.Dst
+	if srcClient != "" {
+		srcEnd.ClientID = RandLowerCaseLetterString(10)
+	}
+	if dstClient != "" {
+		dstEnd.ClientID = RandLowerCaseLetterString(10)
+	}
+	for _, pe := range []*PathEnd{&srcEnd, &dstEnd} {
+		pe.ConnectionID = RandLowerCaseLetterString(10)
+		pe.ChannelID = RandLowerCaseLetterString(10)
+	}
+
+	recovered := *path
+	recovered.Src, recovered.Dst, recovered.Link = &srcEnd, &dstEnd, nil
+	pr := &PathRecovery{Old: path, New: &recovered, SrcClient: srcClient, DstClient: dstClient}
+
+	// failing to find the packets left behind shouldn't stop the path from being recovered
+	if err = pr.queryLeftPackets(ctx, src, dst); err != nil {
+		src.Error(fmt.Errorf("failed to query the packets left on the old channel: %w", err))
+	}
+	return pr, nil
+}
+
+// queryLeftPackets queries the packets and acknowledgements that haven't been relayed over the old path
+func (pr *PathRecovery) queryLeftPackets(ctx context.Context, src, dst *Chain) error {
+	strategy, err := pr.Old.GetStrategy()
+	if err != nil {
+		return err
+	}
+
+	sh, err := NewSyncHeaders(ctx, src, dst)
+	if err != nil {
+		return err
+	}
+
+	if pr.Packets, err = UnrelayedSequencesForStrategy(ctx, src, dst, sh, strategy, pr.Old.Ordered()); err != nil {
+		return err
+	}
+	pr.Acks, err = strategy.UnrelayedAcknowledgements(ctx, src, dst, sh)
+	return err
+}
+
+// Relayable returns true if any of the packets or acknowledgements left on the old channel can still
+// be relayed over it, which is assumed if they couldn't be queried
+func (pr *PathRecovery) Relayable() bool {
+	if pr.Packets == nil || pr.Acks == nil {
+		return true
+	}
+	return (pr.DstClient == "" && (len(pr.Packets.Src) > 0 || len(pr.Acks.Src) > 0)) ||
+		(pr.SrcClient == "" && (len(pr.Packets.Dst) > 0 || len(pr.Acks.Dst) > 0))
+}
+
+// Report describes the unusable clients, the new identifiers and the packets left on the old channel.
+// The packets and acks headed to a chain whose client is unusable can no longer be relayed, the others
+// can still be relayed over the old channel with its identifiers.
+func (pr *PathRecovery) Report() string {
+	var (
+		sb       strings.Builder
+		src, dst = pr.Old.Src, pr.Old.Dst
+	)
+
+	for _, end := range []struct {
+		old, new *PathEnd
+		reason   string
+	}{{src, pr.New.Src, pr.SrcClient}, {dst, pr.New.Dst, pr.DstClient}} {
+		if end.reason == "" {
+			fmt.Fprintf(&sb, "[%s]client{%s} can still be used\n", end.old.ChainID, end.old.ClientID)
+			continue
+		}
+		fmt.Fprintf(&sb, "[%s]client{%s} %s, it is replaced by client{%s}\n",
+			end.old.ChainID, end.old.ClientID, end.reason, end.new.ClientID)
+	}
+	fmt.Fprintf(&sb, "new connection: [%s]conn{%s} -> [%s]conn{%s}\n",
+		pr.New.Src.ChainID, pr.New.Src.ConnectionID, pr.New.Dst.ChainID, pr.New.Dst.ConnectionID)
+	fmt.Fprintf(&sb, "new channel: [%s]chan{%s}port{%s} -> [%s]chan{%s}port{%s}\n",
+		pr.New.Src.ChainID, pr.New.Src.ChannelID, pr.New.Src.PortID, pr.New.Dst.ChainID, pr.New.Dst.ChannelID, pr.New.Dst.PortID)
+
+	if pr.Packets == nil || pr.Acks == nil {
+		sb.WriteString("the packets left on the old channel couldn't be queried\n")
+		pr.reportOldPath(&sb)
+		return sb.String()
+	}
+
+	fmt.Fprintf(&sb, "packets left on the old channel [%s]chan{%s}port{%s} -> [%s]chan{%s}port{%s}:\n",
+		src.ChainID, src.ChannelID, src.PortID, dst.ChainID, dst.ChannelID, dst.PortID)
+	var left int
+	for _, l := range []struct {
+		seqs      []uint64
+		kind      string
+		from, to  string
+		toExpired bool
+	}{
+		{pr.Packets.Src, "packets sent", src.ChainID, dst.ChainID, pr.DstClient != ""},
+		{pr.Packets.Dst, "packets sent", dst.ChainID, src.ChainID, pr.SrcClient != ""},
+		{pr.Acks.Src, "acknowledgements written", src.ChainID, dst.ChainID, pr.DstClient != ""},
+		{pr.Acks.Dst, "acknowledgements written", dst.ChainID, src.ChainID, pr.SrcClient != ""},
+	} {
+		if len(l.seqs) == 0 {
+			continue
+		}
+		left += len(l.seqs)
+		status := "can still be relayed over the old channel"
+		if l.toExpired {
+			status = "can no longer be relayed"
+		}
+		fmt.Fprintf(&sb, "  - %d %s on [%s] for [%s] %s: seqs%v\n", len(l.seqs), l.kind, l.from, l.to, status, l.seqs)
+	}
+	if left == 0 {
+		sb.WriteString("  - none\n")
+	}
+	if pr.Relayable() {
+		pr.reportOldPath(&sb)
+	}
+	return sb.String()
+}
+
+// reportOldPath explains how to relay what is left on the old channel
+func (pr *PathRecovery) reportOldPath(sb *strings.Builder) {
+	if pr.OldName == "" {
+		src, dst := pr.Old.Src, pr.Old.Dst
+		fmt.Fprintf(sb, "the old path isn't kept, add it back as [%s]client{%s}conn{%s}chan{%s}port{%s} -> [%s]client{%s}conn{%s}chan{%s}port{%s} to relay what is left on the old channel\n",
+			src.ChainID, src.ClientID, src.ConnectionID, src.ChannelID, src.PortID,
+			dst.ChainID, dst.ClientID, dst.ConnectionID, dst.ChannelID, dst.PortID)
+		return
+	}
+	fmt.Fprintf(sb, "the old path is kept as %s, relay what is left on it with 'rly tx relay %s' and then remove it with 'rly paths delete %s'\n",
+		pr.OldName, pr.OldName, pr.OldName)
+}
@@ -1,6 +1,7 @@
 package relayer
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -15,6 +16,17 @@ type RelayMsgs struct {
 
 	last    bool
 	success bool
+
+	// commit is true if the txs must be included in a block before they are
+	// treated as successful, whatever the chains' broadcast modes are
+	commit bool
+
+	// skipped is true if packet msgs were left out because their
+	// packets are already being relayed by another tx
+	skipped bool
+
+	// hashes of the txs the msgs were sent in
+	srcHash, dstHash string
 }
 
 // Ready returns true if there are messages to relay
@@ -34,38 +46,72 @@ func (r *RelayMsgs) Success() bool {
 	return r.success
 }
 
+// Skipped returns true if some of the packet msgs weren't sent because their packets are already
+// being relayed by another tx, in which case Success is false as those msgs may still fail
+func (r *RelayMsgs) Skipped() bool {
+	return r.skipped
+}
+
 // Send sends the messages with appropriate output
-func (r *RelayMsgs) Send(src, dst *Chain) {
+func (r *RelayMsgs) Send(ctx context.Context, src, dst *Chain) {
 	var failed = false
 	// TODO: maybe figure out a better way to indicate error here?
 
 	// TODO: Parallelize? Maybe?
+	// in a dry run the msgs are logged instead of sent and treated as successful
+	if src.dryRun || dst.dryRun {
+		if len(r.Src) > 0 {
+			src.logDryRun(ctx, r.Src)
+		}
+		if len(r.Dst) > 0 {
+			dst.logDryRun(ctx, r.Dst)
+		}
+		r.success = true
+		return
+	}
+
+	// leave out the packets that are already being relayed
+	var (
+		ok               bool
+		srcMsgs, dstMsgs = len(r.Src), len(r.Dst)
+	)
+	if r.Src, ok = src.store.claim(src, r.Src); !ok {
+		r.Src = nil
+	}
+	if r.Dst, ok = dst.store.claim(dst, r.Dst); !ok {
+		r.Dst = nil
+	}
+	r.skipped = len(r.Src) < srcMsgs || len(r.Dst) < dstMsgs
+
 	if len(r.Src) > 0 {
 		// Submit the transactions to src chain
-		res, err := src.SendMsgs(r.Src)
+		wait := r.commit || src.waitsForCommit()
+		res, err := src.sendMsgs(ctx, r.Src, wait)
 		if err != nil || res.Code != 0 {
 			src.LogFailedTx(res, err, r.Src)
 			failed = true
-		} else {
+		} else if r.srcHash = res.TxHash; wait {
 			// NOTE: Add more data to this such as identifiers
+			// in sync and async modes the result is logged once the tx is included
 			src.LogSuccessTx(res, r.Src)
 		}
 	}
 
 	if len(r.Dst) > 0 {
 		// Submit the transactions to dst chain
-		res, err := dst.SendMsgs(r.Dst)
+		wait := r.commit || dst.waitsForCommit()
+		res, err := dst.sendMsgs(ctx, r.Dst, wait)
 		if err != nil || res.Code != 0 {
 			dst.LogFailedTx(res, err, r.Dst)
 			failed = true
-		} else {
+		} else if r.dstHash = res.TxHash; wait {
 			// NOTE: Add more data to this such as identifiers
+			// in sync and async modes the result is logged once the tx is included
 			dst.LogSuccessTx(res, r.Dst)
-
 		}
 	}
 
-	if failed {
+	if failed || r.skipped {
 		r.success = false
 		return
 	}
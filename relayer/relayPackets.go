@@ -1,18 +1,40 @@
 package relayer
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
 	retry "github.com/avast/retry-go"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	chanState "github.com/cosmos/cosmos-sdk/x/ibc/04-channel/exported"
+	chanTypes "github.com/cosmos/cosmos-sdk/x/ibc/04-channel/types"
+	tmclient "github.com/cosmos/cosmos-sdk/x/ibc/07-tendermint/types"
 )
 
 type relayPacket interface {
 	Msg(src, dst *Chain) sdk.Msg
-	FetchCommitResponse(src, dst *Chain, sh *SyncHeaders) error
+	FetchCommitResponse(ctx context.Context, src, dst *Chain, sh *SyncHeaders) error
 	Data() []byte
 	Seq() uint64
 	Timeout() uint64
+	Relayed(ctx context.Context, src, dst *Chain, sh *SyncHeaders) (bool, error)
+}
+
+// relayPacketFromMsg returns the relayPacket that the packet msg was built from, without
+// its proof, so that it can be relayed again with a new one
+func relayPacketFromMsg(msg sdk.Msg) (relayPacket, bool) {
+	switch m := msg.(type) {
+	case chanTypes.MsgPacket:
+		return &relayMsgRecvPacket{packetData: m.Data, seq: m.Sequence, timeout: m.TimeoutHeight, timeoutStamp: m.TimeoutTimestamp}, true
+	case chanTypes.MsgAcknowledgement:
+		return &relayMsgPacketAck{packetData: m.Data, ack: m.Acknowledgement, seq: m.Sequence,
+			timeout: m.TimeoutHeight, timeoutStamp: m.TimeoutTimestamp}, true
+	case chanTypes.MsgTimeout:
+		return &relayMsgTimeout{packetData: m.Data, seq: m.Sequence, timeout: m.TimeoutHeight, timeoutStamp: m.TimeoutTimestamp}, true
+	default:
+		return nil, false
+	}
 }
 
 type relayMsgRecvPacket struct {
@@ -37,19 +59,19 @@ func (rp *relayMsgRecvPacket) Timeout() uint64 {
 	return rp.timeout
 }
 
-func (rp *relayMsgRecvPacket) FetchCommitResponse(src, dst *Chain, sh *SyncHeaders) (err error) {
+func (rp *relayMsgRecvPacket) FetchCommitResponse(ctx context.Context, src, dst *Chain, sh *SyncHeaders) (err error) {
 	var dstCommitRes CommitmentResponse
 
 	// retry getting commit response until it succeeds
 	if err = retry.Do(func() error {
-		dstCommitRes, err = dst.QueryPacketCommitment(int64(sh.GetHeight(dst.ChainID)-1), int64(rp.seq))
+		dstCommitRes, err = dst.QueryPacketCommitment(ctx, int64(sh.GetHeight(dst.ChainID)-1), int64(rp.seq))
 		if err != nil {
 			return err
 		} else if dstCommitRes.Proof.Proof == nil {
 			return fmt.Errorf("- [%s]@{%d} - Packet Commitment Proof is nil seq(%d)", dst.ChainID, int64(sh.GetHeight(dst.ChainID)-1), rp.seq)
 		}
 		return nil
-	}); err != nil {
+	}, retryUntilDone(ctx)); err != nil {
 		dst.Error(err)
 		return
 	}
@@ -58,6 +80,47 @@ func (rp *relayMsgRecvPacket) FetchCommitResponse(src, dst *Chain, sh *SyncHeade
 	return
 }
 
+// timedOut returns true if the packet can no longer be received by the chain
+// with the given header and must be timed out on the sending chain instead
+func (rp *relayMsgRecvPacket) timedOut(hdr *tmclient.Header) bool {
+	if hdr == nil {
+		return false
+	}
+	return (rp.timeout != 0 && hdr.GetHeight() >= rp.timeout) ||
+		(rp.timeoutStamp != 0 && uint64(hdr.Time.UnixNano()) >= rp.timeoutStamp)
+}
+
+// timeoutPacket returns a relayMsgTimeout for the packet
+func (rp *relayMsgRecvPacket) timeoutPacket() *relayMsgTimeout {
+	return &relayMsgTimeout{
+		packetData:   rp.packetData,
+		seq:          rp.seq,
+		timeout:      rp.timeout,
+		timeoutStamp: rp.timeoutStamp,
+	}
+}
+
+// Relayed returns true if src has already received the packet
+func (rp *relayMsgRecvPacket) Relayed(ctx context.Context, src, dst *Chain, sh *SyncHeaders) (bool, error) {
+	height := int64(sh.GetHeight(src.ChainID))
+	if src.PathEnd.getOrder() == chanState.ORDERED {
+		recvRes, err := src.QueryNextSeqRecv(ctx, height)
+		if err != nil {
+			return false, err
+		}
+		return recvRes.NextSequenceRecv > rp.seq, nil
+	}
+
+	// an ack is always written when an UNORDERED channel receives a packet
+	if _, err := src.QueryPacketAckAbsence(ctx, height, int64(rp.seq)); err != nil {
+		if errors.Is(err, errPacketReceived) {
+			return true, nil
+		}
+		return false, err
+	}
+	return false, nil
+}
+
 func (rp *relayMsgRecvPacket) Msg(src, dst *Chain) sdk.Msg {
 	if rp.dstComRes == nil {
 		return nil
@@ -88,6 +151,11 @@ func (rp *relayMsgPacketAck) Timeout() uint64 {
 	return rp.timeout
 }
 
+// Relayed returns true if src, which sent the packet, has already processed the ack
+func (rp *relayMsgPacketAck) Relayed(ctx context.Context, src, dst *Chain, sh *SyncHeaders) (bool, error) {
+	return packetCommitmentDeleted(ctx, src, sh, rp.seq)
+}
+
 func (rp *relayMsgPacketAck) Msg(src, dst *Chain) sdk.Msg {
 	return src.PathEnd.MsgAck(
 		dst.PathEnd,
@@ -102,17 +170,104 @@ func (rp *relayMsgPacketAck) Msg(src, dst *Chain) sdk.Msg {
 	)
 }
 
-func (rp *relayMsgPacketAck) FetchCommitResponse(src, dst *Chain, sh *SyncHeaders) (err error) {
+func (rp *relayMsgPacketAck) FetchCommitResponse(ctx context.Context, src, dst *Chain, sh *SyncHeaders) (err error) {
 	var dstCommitRes CommitmentResponse
 	if err = retry.Do(func() error {
-		dstCommitRes, err = dst.QueryPacketAck(int64(sh.GetHeight(dst.ChainID)-1), int64(rp.seq))
+		dstCommitRes, err = dst.QueryPacketAck(ctx, int64(sh.GetHeight(dst.ChainID)-1), int64(rp.seq))
 		if err != nil {
 			return err
 		} else if dstCommitRes.Proof.Proof == nil {
 			return fmt.Errorf("- [%s]@{%d} - Packet Ack Proof is nil seq(%d)", dst.ChainID, int64(sh.GetHeight(dst.ChainID)-1), rp.seq)
 		}
 		return nil
-	}); err != nil {
+	}, retryUntilDone(ctx)); err != nil {
+		dst.Error(err)
+		return
+	}
+	rp.dstComRes = &dstCommitRes
+	return nil
+}
+
+type relayMsgTimeout struct {
+	packetData   []byte
+	seq          uint64
+	timeout      uint64
+	timeoutStamp uint64
+	nextSeqRecv  uint64
+	dstComRes    *CommitmentResponse
+
+	pass bool
+}
+
+func (rp *relayMsgTimeout) Data() []byte {
+	return rp.packetData
+}
+func (rp *relayMsgTimeout) Seq() uint64 {
+	return rp.seq
+}
+func (rp *relayMsgTimeout) Timeout() uint64 {
+	return rp.timeout
+}
+
+// Relayed returns true if src, which sent the packet, has already processed the timeout
+// or an ack for the packet
+func (rp *relayMsgTimeout) Relayed(ctx context.Context, src, dst *Chain, sh *SyncHeaders) (bool, error) {
+	return packetCommitmentDeleted(ctx, src, sh, rp.seq)
+}
+
+// packetCommitmentDeleted returns true if the chain no longer has the commitment for the
+// packet it sent with the given seq, which is deleted once the packet is acked or timed out
+func packetCommitmentDeleted(ctx context.Context, c *Chain, sh *SyncHeaders, seq uint64) (bool, error) {
+	comRes, err := c.QueryPacketCommitment(ctx, int64(sh.GetHeight(c.ChainID)), int64(seq))
+	if err != nil {
+		return false, err
+	}
+	return len(comRes.Data) == 0, nil
+}
+
+func (rp *relayMsgTimeout) Msg(src, dst *Chain) sdk.Msg {
+	if rp.dstComRes == nil {
+		return nil
+	}
+	return src.PathEnd.MsgTimeout(
+		dst.PathEnd,
+		rp.seq,
+		rp.timeout,
+		rp.timeoutStamp,
+		rp.nextSeqRecv,
+		rp.packetData,
+		rp.dstComRes.Proof,
+		rp.dstComRes.ProofHeight,
+		src.MustGetAddress(),
+	)
+}
+
+// FetchCommitResponse fetches the proof that dst has not received the packet, for ORDERED
+// channels this is the next sequence recv and for UNORDERED channels it is the absence of the ack
+func (rp *relayMsgTimeout) FetchCommitResponse(ctx context.Context, src, dst *Chain, sh *SyncHeaders) (err error) {
+	var (
+		dstCommitRes CommitmentResponse
+		height       = int64(sh.GetHeight(dst.ChainID) - 1)
+	)
+	if err = retry.Do(func() error {
+		if dst.PathEnd.getOrder() == chanState.ORDERED {
+			recvRes, err := dst.QueryNextSeqRecv(ctx, height)
+			if err != nil {
+				return err
+			}
+			rp.nextSeqRecv = recvRes.NextSequenceRecv
+			dstCommitRes = CommitmentResponse{Proof: recvRes.Proof, ProofPath: recvRes.ProofPath, ProofHeight: recvRes.ProofHeight}
+		} else if dstCommitRes, err = dst.QueryPacketAckAbsence(ctx, height, int64(rp.seq)); err != nil {
+			if errors.Is(err, errPacketReceived) {
+				return retry.Unrecoverable(err)
+			}
+			return err
+		}
+		if dstCommitRes.Proof.Proof == nil {
+			return fmt.Errorf("- [%s]@{%d} - Packet Timeout Proof is nil seq(%d)", dst.ChainID, height, rp.seq)
+		}
+		return nil
+	}, retryUntilDone(ctx)); err != nil {
 		dst.Error(err)
 		return
 	}
@@ -0,0 +1,171 @@
+package relayer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	chanState "github.com/cosmos/cosmos-sdk/x/ibc/04-channel/exported"
+)
+
+const (
+	retryParamMaxAttempts = "max-retries"
+	retryParamDelay       = "retry-delay"
+)
+
+var (
+	defaultRetryMaxAttempts = 5
+	defaultRetryDelay       = time.Second
+	retryMaxDelay           = time.Minute * 5
+)
+
+// retryParams are the params shared by the strategies that use a retryQueue
+var retryParams = []string{retryParamMaxAttempts, retryParamDelay}
+
+// retryQueue holds the packets from relay transactions that failed to send and retries
+// them with exponential backoff, fetching new proofs on each attempt. Packets that the
+// receiving chain shows as already relayed are dropped, as are batches that still fail
+// after MaxAttempts retries or once the context they were relayed with is done. Packets
+// that time out while they are being retried are queued to be timed out instead.
+type retryQueue struct {
+	MaxAttempts int
+	Delay       time.Duration
+}
+
+// retryBatch is a set of packets to be relayed to src together
+type retryBatch struct {
+	ctx      context.Context
+	src, dst *Chain
+	sh       *SyncHeaders
+	packets  []relayPacket
+	attempts int
+}
+
+// newRetryQueue returns a retryQueue configured from the strategy params
+func newRetryQueue(cfg *StrategyCfg) (rq *retryQueue, err error) {
+	rq = &retryQueue{}
+	if rq.MaxAttempts, err = cfg.IntParam(retryParamMaxAttempts, defaultRetryMaxAttempts); err != nil {
+		return nil, err
+	}
+	if rq.Delay, err = cfg.DurationParam(retryParamDelay, defaultRetryDelay); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case rq.MaxAttempts < 0:
+		return nil, fmt.Errorf("%s can't be negative, got %d", retryParamMaxAttempts, rq.MaxAttempts)
+	case rq.Delay <= 0:
+		return nil, fmt.Errorf("%s must be positive, got %s", retryParamDelay, rq.Delay)
+	}
+	return rq, nil
+}
+
+// add queues the packets, which failed to be relayed to src, to be retried
+func (rq *retryQueue) add(ctx context.Context, src, dst *Chain, packets []relayPacket, sh *SyncHeaders) {
+	if len(packets) == 0 {
+		return
+	}
+	if rq == nil || rq.MaxAttempts == 0 || ctx.Err() != nil {
+		src.Error(fmt.Errorf("failed to relay %d packets, not retrying", len(packets)))
+		return
+	}
+	rq.schedule(&retryBatch{ctx: ctx, src: src, dst: dst, sh: sh, packets: packets})
+}
+
+// schedule waits out the backoff for the batch's next attempt and then retries it
+func (rq *retryQueue) schedule(b *retryBatch) {
+	b.attempts++
+	delay := rq.delay(b.attempts)
+
+	b.src.Log(fmt.Sprintf("- retrying %d packets to %s in %s, attempt %d/%d", len(b.packets), b.src.ChainID, delay, b.attempts, rq.MaxAttempts))
+	time.AfterFunc(delay, func() { rq.retry(b) })
+}
+
+// delay returns the backoff before the given attempt, which starts at Delay
+// and doubles with each attempt up to retryMaxDelay
+func (rq *retryQueue) delay(attempt int) time.Duration {
+	delay := rq.Delay << uint(attempt-1)
+	if delay <= 0 || delay > retryMaxDelay {
+		delay = retryMaxDelay
+	}
+	return delay
+}
+
+// exhausted returns true if the batch has been retried MaxAttempts times and should be dropped
+func (rq *retryQueue) exhausted(b *retryBatch) bool {
+	return b.attempts >= rq.MaxAttempts
+}
+
+// retry sends the packets in the batch that haven't been relayed yet, with proofs fetched
+// against freshly updated headers, scheduling another attempt if the transaction fails
+func (rq *retryQueue) retry(b *retryBatch) {
+	ctx, src, dst := b.ctx, b.src, b.dst
+	if ctx.Err() != nil {
+		return
+	}
+	if err := b.sh.Update(ctx, src); err != nil {
+		src.Error(err)
+	}
+	if err := b.sh.Update(ctx, dst); err != nil {
+		dst.Error(err)
+	}
+
+	// packets that have timed out on src while they were being retried are timed out on dst instead
+	var timeouts []relayPacket
+	b.packets, timeouts = splitTimedOutPackets(b.packets, b.sh.GetHeader(src.ChainID), src.PathEnd.getOrder() == chanState.ORDERED)
+	rq.add(ctx, dst, src, timeouts, b.sh)
+
+	txs := &RelayMsgs{
+		Src: []sdk.Msg{src.PathEnd.UpdateClient(b.sh.GetHeader(dst.ChainID), src.MustGetAddress())},
+		Dst: []sdk.Msg{},
+	}
+
+	// unsent holds the packets that still need to be relayed if the transaction succeeds
+	pending, unsent := []relayPacket{}, []relayPacket{}
+	for _, rp := range b.packets {
+		relayed, err := rp.Relayed(ctx, src, dst, b.sh)
+		switch {
+		case err != nil:
+			// keep the packet, it is dropped if it has been relayed by the next attempt
+			src.Error(fmt.Errorf("failed to check if packet seq(%d) has been relayed: %w", rp.Seq(), err))
+		case relayed:
+			src.Log(fmt.Sprintf("- packet seq(%d) has already been relayed to %s, dropping retry", rp.Seq(), src.ChainID))
+			continue
+		}
+
+		pending = append(pending, rp)
+		if err = rp.FetchCommitResponse(ctx, src, dst, b.sh); err != nil {
+			src.Error(err)
+			unsent = append(unsent, rp)
+			continue
+		}
+		txs.Src = append(txs.Src, rp.Msg(src, dst))
+	}
+	b.packets = pending
+
+	if len(txs.Src) > 1 {
+		if txs.Send(ctx, src, dst); txs.success {
+			// packets that are already being relayed by another tx are left out when it is sent
+			if len(txs.Src) > 1 {
+				src.logPacketsRelayed(dst, len(txs.Src)-1)
+			}
+			b.packets = unsent
+		}
+	}
+	if len(b.packets) == 0 {
+		return
+	}
+
+	// packets that are in flight in another tx don't use up an attempt, they are dropped
+	// once that tx is included or sent again once the store gives up waiting for it
+	if txs.Skipped() && !txs.Ready() {
+		b.attempts--
+	}
+
+	if rq.exhausted(b) {
+		src.Error(fmt.Errorf("failed to relay %d packets to %s after %d retries, dropping them", len(b.packets), src.ChainID, b.attempts))
+		return
+	}
+	rq.schedule(b)
+}
@@ -0,0 +1,89 @@
+package relayer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRetryQueue(t *testing.T) {
+	cases := []struct {
+		name     string
+		params   map[string]string
+		attempts int
+		delay    time.Duration
+		valid    bool
+	}{
+		{"defaults", nil, defaultRetryMaxAttempts, defaultRetryDelay, true},
+		{"set", map[string]string{retryParamMaxAttempts: "2", retryParamDelay: "3s"}, 2, time.Second * 3, true},
+		{"disabled", map[string]string{retryParamMaxAttempts: "0"}, 0, defaultRetryDelay, true},
+		{"negative attempts", map[string]string{retryParamMaxAttempts: "-1"}, 0, 0, false},
+		{"zero delay", map[string]string{retryParamDelay: "0s"}, 0, 0, false},
+		{"invalid delay", map[string]string{retryParamDelay: "soon"}, 0, 0, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rq, err := newRetryQueue(&StrategyCfg{Type: "naive", Params: tc.params})
+			if !tc.valid {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.attempts, rq.MaxAttempts)
+			require.Equal(t, tc.delay, rq.Delay)
+		})
+	}
+}
+
+func TestRetryQueueDelay(t *testing.T) {
+	cases := []struct {
+		name     string
+		delay    time.Duration
+		attempt  int
+		expected time.Duration
+	}{
+		{"first attempt", time.Second, 1, time.Second},
+		{"second attempt", time.Second, 2, time.Second * 2},
+		{"fifth attempt", time.Second, 5, time.Second * 16},
+		{"capped", time.Second, 10, retryMaxDelay},
+		{"delay above the cap", retryMaxDelay * 2, 1, retryMaxDelay},
+		{"overflow", time.Second, 64, retryMaxDelay},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rq := &retryQueue{MaxAttempts: defaultRetryMaxAttempts, Delay: tc.delay}
+			require.Equal(t, tc.expected, rq.delay(tc.attempt))
+		})
+	}
+}
+
+func TestRetryQueueExhausted(t *testing.T) {
+	cases := []struct {
+		name        string
+		maxAttempts int
+		attempts    int
+		exhausted   bool
+	}{
+		{"before the last attempt", 3, 2, false},
+		{"after the last attempt", 3, 3, true},
+		{"single attempt", 1, 1, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rq := &retryQueue{MaxAttempts: tc.maxAttempts, Delay: time.Second}
+			require.Equal(t, tc.exhausted, rq.exhausted(&retryBatch{attempts: tc.attempts}))
+		})
+	}
+}
+
+func TestRetryQueueAddNoPackets(t *testing.T) {
+	// nothing is queued or logged, so the chains are never used
+	rq := &retryQueue{MaxAttempts: defaultRetryMaxAttempts, Delay: time.Second}
+	require.NotPanics(t, func() { rq.add(context.Background(), nil, nil, nil, nil) })
+	require.NotPanics(t, func() { rq.add(context.Background(), nil, nil, []relayPacket{}, nil) })
+}
@@ -0,0 +1,166 @@
+package relayer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	sdkCtx "github.com/cosmos/cosmos-sdk/client/context"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+)
+
+const (
+	broadcastModeCommit = "commit"
+	broadcastModeSync   = "sync"
+	broadcastModeAsync  = "async"
+)
+
+var (
+	// txConfirmTimeout is how long to wait for a broadcast tx to be included in a block
+	txConfirmTimeout = time.Minute
+
+	// txPollInterval is how often to query for a broadcast tx while waiting for it
+	txPollInterval = time.Second
+)
+
+// txSigner signs and broadcasts txs for a chain's relayer key. The account sequence is
+// tracked locally rather than queried for every tx, so txs can be broadcast without
+// waiting for the previous one to be included in a block.
+type txSigner struct {
+	mu     sync.Mutex
+	accNum uint64
+	seq    uint64
+	synced bool
+}
+
+// broadcast signs the msgs with the next account sequence and broadcasts the tx with the given
+// mode. The sequence is only used up if the tx is accepted, and if the tx is rejected for an
+// incorrect sequence the sequence is fetched from the chain and the tx is signed again.
+func (ts *txSigner) broadcast(ctx context.Context, c *Chain, msgs []sdk.Msg, mode string) (res sdk.TxResponse, err error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	for attempt := 0; attempt < 2; attempt++ {
+		if !ts.synced {
+			if err = ts.syncLocked(ctx, c); err != nil {
+				return res, err
+			}
+		}
+
+		var txBytes []byte
+		if txBytes, err = c.signTx(ctx, c.Key, ts.accNum, ts.seq, msgs); err != nil {
+			return res, err
+		}
+
+		var out sdk.TxResponse
+		if err = rpcCall(ctx, func() (err error) {
+			out, err = sdkCtx.CLIContext{Client: c.Client, BroadcastMode: mode}.BroadcastTx(txBytes)
+			return err
+		}); err == nil {
+			res = out
+		}
+		switch {
+		case err != nil:
+			// the tx may or may not have reached the mempool
+			ts.synced = false
+			return res, err
+		case isSequenceErr(res):
+			if c.debug {
+				c.Log(fmt.Sprintf("- [%s] -> incorrect account sequence(%d), resyncing", c.ChainID, ts.seq))
+			}
+			ts.synced = false
+			continue
+		case res.Code == 0:
+			ts.seq++
+		}
+		return res, nil
+	}
+	return res, nil
+}
+
+// resync causes the account sequence to be fetched from the chain before the next tx is signed
+func (ts *txSigner) resync() {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.synced = false
+}
+
+// syncLocked fetches the account number and sequence from the chain, the caller must hold ts.mu
+func (ts *txSigner) syncLocked(ctx context.Context, c *Chain) error {
+	addr, err := c.GetAddress()
+	if err != nil {
+		return err
+	}
+	acc, err := auth.NewAccountRetriever(c.Cdc, c.querier(ctx)).GetAccount(addr)
+	if err != nil {
+		return err
+	}
+	ts.accNum, ts.seq, ts.synced = acc.GetAccountNumber(), acc.GetSequence(), true
+	return nil
+}
+
+// isSequenceErr returns true if the tx was rejected because it was signed with the wrong sequence
+func isSequenceErr(res sdk.TxResponse) bool {
+	return res.Codespace == sdkerrors.RootCodespace && res.Code == sdkerrors.ErrUnauthorized.ABCICode() &&
+		strings.Contains(res.RawLog, "account sequence")
+}
+
+// waitForTx polls for the tx with the given hash until it has been included in a block or
+// txConfirmTimeout has passed. If the tx isn't found the account sequence is resynced, as
+// the tx may have been dropped from the mempool. If ctx is done first, ctx.Err() is returned.
+func (src *Chain) waitForTx(ctx context.Context, hash string) (sdk.TxResponse, error) {
+	deadline := time.Now().Add(txConfirmTimeout)
+	for {
+		res, err := src.QueryTx(ctx, hash)
+		if err == nil {
+			if !src.debug {
+				res.RawLog = ""
+			}
+			return res, nil
+		}
+
+		if time.Now().After(deadline) {
+			src.signer.resync()
+			return sdk.TxResponse{TxHash: hash}, fmt.Errorf("tx(%s) not included in a block after %s: %w", hash, txConfirmTimeout, err)
+		}
+		select {
+		case <-ctx.Done():
+			return sdk.TxResponse{TxHash: hash}, ctx.Err()
+		case <-time.After(txPollInterval):
+		}
+	}
+}
+
+// trackTx waits for the tx to be included in a block and logs the result. If the relayer
+// stops first, the packets are left submitted for the next run to resolve from the tx.
+func (src *Chain) trackTx(ctx context.Context, hash string, msgs []sdk.Msg) {
+	res, err := src.waitForTx(ctx, hash)
+	if err != nil && ctx.Err() != nil {
+		return
+	}
+	src.store.included(src, msgs, res, err)
+	if err != nil || res.Code != 0 {
+		src.LogFailedTx(res, err, msgs)
+		return
+	}
+	src.LogSuccessTx(res, msgs)
+}
+
+// waitsForCommit returns true if SendMsgs waits for txs to be included in a block
+func (src *Chain) waitsForCommit() bool {
+	return src.BroadcastMode == "" || src.BroadcastMode == broadcastModeCommit
+}
+
+func validateBroadcastMode(mode string) error {
+	switch mode {
+	case "", broadcastModeCommit, broadcastModeSync, broadcastModeAsync:
+		return nil
+	default:
+		return fmt.Errorf("broadcast mode must be one of %s, %s or %s, got %s",
+			broadcastModeCommit, broadcastModeSync, broadcastModeAsync, mode)
+	}
+}
@@ -0,0 +1,406 @@
+package relayer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	chanTypes "github.com/cosmos/cosmos-sdk/x/ibc/04-channel/types"
+	dbm "github.com/tendermint/tm-db"
+)
+
+// PacketState is the relay state of a packet recorded in the RelayStore
+type PacketState string
+
+const (
+	// PacketSeen packets have been seen in events but not yet submitted
+	PacketSeen PacketState = "seen"
+	// PacketSubmitted packets are in a tx that has been broadcast but not yet included in a block
+	PacketSubmitted PacketState = "submitted"
+	// PacketConfirmed packets were relayed in a tx that was included in a block
+	PacketConfirmed PacketState = "confirmed"
+	// PacketTimedOut packets were timed out on the sending chain in a tx that was included in a block
+	PacketTimedOut PacketState = "timed-out"
+)
+
+const (
+	packetMsgRecv    = "recv"
+	packetMsgAck     = "ack"
+	packetMsgTimeout = "timeout"
+
+	packetPrefix = "packet/"
+)
+
+var (
+	// storeSubmitTimeout is how long a packet stays submitted before it can be relayed
+	// again, this covers txs whose result was never seen
+	storeSubmitTimeout = txConfirmTimeout * 2
+
+	// storeRetention is how long the records of relayed packets are kept
+	storeRetention = time.Hour * 24
+)
+
+// PacketRecord is the relay state of a packet
+type PacketRecord struct {
+	State   PacketState `json:"state"`
+	TxHash  string      `json:"tx-hash,omitempty"`
+	Updated time.Time   `json:"updated"`
+}
+
+// final returns true if the packet has been relayed or timed out
+func (pr *PacketRecord) final() bool {
+	return pr.State == PacketConfirmed || pr.State == PacketTimedOut
+}
+
+// inFlight returns true if the packet is being relayed and shouldn't be submitted again
+func (pr *PacketRecord) inFlight() bool {
+	return pr.State == PacketSubmitted && time.Since(pr.Updated) < storeSubmitTimeout
+}
+
+// RelayStore records the relay state of packets so that the relayer skips the packets
+// that are already being relayed and resumes cleanly after a restart. Packets are keyed
+// by the chain and channel end their relay msg is sent to, the msg type and the sequence,
+// so a single store is shared by every path. A nil RelayStore records nothing.
+type RelayStore struct {
+	mu sync.Mutex
+	db dbm.DB
+}
+
+// OpenRelayStore opens the relay store in the home directory, next to the lite client databases
+// CONTRACT: must close the store when done with it, only one process can have it open at a time
+func OpenRelayStore(home string) (*RelayStore, error) {
+	db, err := dbm.NewGoLevelDB("relay", storeDir(home))
+	if err != nil {
+		return nil, fmt.Errorf("can't open relay store: %w", err)
+	}
+	return &RelayStore{db: db}, nil
+}
+
+// Close closes the database connection
+func (s *RelayStore) Close() error {
+	return s.db.Close()
+}
+
+// packetKey identifies the relay msg for a packet by the chain and channel end it is sent to
+type packetKey struct {
+	chainID, portID, channelID string
+	msgType                    string
+	seq                        uint64
+}
+
+func (k packetKey) bytes() []byte {
+	return []byte(fmt.Sprintf("%s%s/%s/%s/%s/%020d", packetPrefix, k.chainID, k.portID, k.channelID, k.msgType, k.seq))
+}
+
+func (k packetKey) String() string {
+	return fmt.Sprintf("%s seq(%d) on [%s]", k.msgType, k.seq, k.chainID)
+}
+
+// parsePacketKey parses a key written by packetKey.bytes
+func parsePacketKey(bz []byte) (k packetKey, err error) {
+	parts := strings.Split(strings.TrimPrefix(string(bz), packetPrefix), "/")
+	if len(parts) != 5 {
+		return k, fmt.Errorf("invalid packet key")
+	}
+	if k.seq, err = strconv.ParseUint(parts[4], 10, 64); err != nil {
+		return k, err
+	}
+	k.chainID, k.portID, k.channelID, k.msgType = parts[0], parts[1], parts[2], parts[3]
+	return k, nil
+}
+
+// channelPrefix returns the prefix of the keys for the packets relayed to the chain's path end
+func channelPrefix(c *Chain) []byte {
+	return []byte(fmt.Sprintf("%s%s/%s/%s/", packetPrefix, c.ChainID, c.PathEnd.PortID, c.PathEnd.ChannelID))
+}
+
+// relayPacketKey returns the key for a packet to be relayed to c
+func relayPacketKey(c *Chain, rp relayPacket) packetKey {
+	k := packetKey{chainID: c.ChainID, portID: c.PathEnd.PortID, channelID: c.PathEnd.ChannelID, seq: rp.Seq()}
+	switch rp.(type) {
+	case *relayMsgRecvPacket:
+		k.msgType = packetMsgRecv
+	case *relayMsgPacketAck:
+		k.msgType = packetMsgAck
+	case *relayMsgTimeout:
+		k.msgType = packetMsgTimeout
+	}
+	return k
+}
+
+// msgPacketKey returns the key for a msg sent to c, ok is false if it doesn't relay a packet
+func msgPacketKey(c *Chain, msg sdk.Msg) (k packetKey, ok bool) {
+	switch m := msg.(type) {
+	case chanTypes.MsgPacket:
+		return packetKey{c.ChainID, m.DestinationPort, m.DestinationChannel, packetMsgRecv, m.Sequence}, true
+	case chanTypes.MsgAcknowledgement:
+		return packetKey{c.ChainID, m.SourcePort, m.SourceChannel, packetMsgAck, m.Sequence}, true
+	case chanTypes.MsgTimeout:
+		return packetKey{c.ChainID, m.SourcePort, m.SourceChannel, packetMsgTimeout, m.Sequence}, true
+	default:
+		return packetKey{}, false
+	}
+}
+
+// get returns the record for the key, or nil if there isn't one
+func (s *RelayStore) get(k packetKey) (*PacketRecord, error) {
+	bz, err := s.db.Get(k.bytes())
+	if err != nil || bz == nil {
+		return nil, err
+	}
+	pr := &PacketRecord{}
+	if err = json.Unmarshal(bz, pr); err != nil {
+		return nil, fmt.Errorf("invalid record for %s: %w", k, err)
+	}
+	return pr, nil
+}
+
+// set writes the record for the key
+func (s *RelayStore) set(k packetKey, state PacketState, hash string) error {
+	bz, err := json.Marshal(&PacketRecord{State: state, TxHash: hash, Updated: time.Now()})
+	if err != nil {
+		return err
+	}
+	return s.db.SetSync(k.bytes(), bz)
+}
+
+// observe records the packets to be relayed to c as seen and returns
+// the ones that haven't already been submitted or relayed
+func (s *RelayStore) observe(c *Chain, rlyPackets []relayPacket) []relayPacket {
+	if s == nil {
+		return rlyPackets
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := []relayPacket{}
+	for _, rp := range rlyPackets {
+		k := relayPacketKey(c, rp)
+		pr, err := s.get(k)
+		switch {
+		case err != nil:
+			c.Error(err)
+		case pr == nil:
+			if err = s.set(k, PacketSeen, ""); err != nil {
+				c.Error(err)
+			}
+		case pr.final() || pr.inFlight():
+			c.Log(fmt.Sprintf("- skipping %s, already %s", k, pr.State))
+			continue
+		}
+		out = append(out, rp)
+	}
+	return out
+}
+
+// claim marks the packets relayed by the msgs to c as submitted and returns the msgs without the
+// ones for packets that are already submitted or relayed. Msgs that don't relay a packet are kept.
+// If every packet msg is left out, ok is false and the msgs shouldn't be sent.
+func (s *RelayStore) claim(c *Chain, msgs []sdk.Msg) (out []sdk.Msg, ok bool) {
+	if s == nil {
+		return msgs, true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var packets, claimed int
+	for _, msg := range msgs {
+		k, isPacket := msgPacketKey(c, msg)
+		if !isPacket {
+			out = append(out, msg)
+			continue
+		}
+
+		packets++
+		pr, err := s.get(k)
+		switch {
+		case err != nil:
+			c.Error(err)
+		case pr != nil && (pr.final() || pr.inFlight()):
+			c.Log(fmt.Sprintf("- skipping %s, already %s", k, pr.State))
+			continue
+		}
+		if err = s.set(k, PacketSubmitted, ""); err != nil {
+			c.Error(err)
+		}
+		out = append(out, msg)
+		claimed++
+	}
+	return out, packets == 0 || claimed > 0
+}
+
+// submitted records the hash of the tx the msgs were broadcast to c in
+func (s *RelayStore) submitted(c *Chain, hash string, msgs []sdk.Msg) {
+	s.update(c, msgs, func(string) (PacketState, bool) { return PacketSubmitted, true }, hash)
+}
+
+// released removes the records of the packets relayed by the msgs to c, so
+// that they are relayed again, as the tx they were sent in failed
+func (s *RelayStore) released(c *Chain, msgs []sdk.Msg) {
+	s.update(c, msgs, func(string) (PacketState, bool) { return "", false }, "")
+}
+
+// included records the result of the tx the msgs were sent to c in, releasing the packets if it failed
+func (s *RelayStore) included(c *Chain, msgs []sdk.Msg, res sdk.TxResponse, err error) {
+	if err != nil || res.Code != 0 {
+		s.released(c, msgs)
+		return
+	}
+	s.update(c, msgs, func(msgType string) (PacketState, bool) {
+		if msgType == packetMsgTimeout {
+			return PacketTimedOut, true
+		}
+		return PacketConfirmed, true
+	}, res.TxHash)
+}
+
+// update sets the state of the packets relayed by the msgs to c, the record
+// is deleted if state returns false
+func (s *RelayStore) update(c *Chain, msgs []sdk.Msg, state func(msgType string) (PacketState, bool), hash string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, msg := range msgs {
+		k, ok := msgPacketKey(c, msg)
+		if !ok {
+			continue
+		}
+
+		var err error
+		if st, ok := state(k.msgType); ok {
+			err = s.set(k, st, hash)
+		} else {
+			err = s.db.DeleteSync(k.bytes())
+		}
+		if err != nil {
+			c.Error(fmt.Errorf("failed to update %s in relay store: %w", k, err))
+		}
+	}
+}
+
+// resume resolves the packets relayed to c's path end that were left in flight by a previous
+// run. Packets submitted in a tx that was included are marked with its result, the ones that
+// never made it into a tx are released and the records of old relayed packets are pruned.
+// The txs are queried without holding the lock, so the other paths sharing the store aren't
+// held up while they are fetched.
+func (s *RelayStore) resume(ctx context.Context, c *Chain) error {
+	if s == nil {
+		return nil
+	}
+
+	keys, hashes, resolved, err := s.pending(c)
+	if err != nil {
+		return fmt.Errorf("failed to resume relay store for %s: %w", c.ChainID, err)
+	}
+
+	txs := make(map[string]sdk.TxResponse)
+	for _, hash := range hashes {
+		if _, ok := txs[hash]; ok {
+			continue
+		}
+		// if the tx isn't found it may still be in the mempool, the packet
+		// is relayed again once it has been submitted for storeSubmitTimeout
+		if res, err := c.QueryTx(ctx, hash); err == nil {
+			txs[hash] = res
+		} else if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+
+	n, err := s.resolve(keys, hashes, txs)
+	if err != nil {
+		return fmt.Errorf("failed to resume relay store for %s: %w", c.ChainID, err)
+	}
+
+	if resolved += n; resolved > 0 {
+		c.Log(fmt.Sprintf("- resolved %d packets left in flight on %s", resolved, c.ChainID))
+	}
+	return nil
+}
+
+// pending prunes old records of relayed packets on c's path end and releases the packets
+// submitted without a tx, returning the keys and tx hashes of the other submitted packets
+func (s *RelayStore) pending(c *Chain) (keys []packetKey, hashes []string, released int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// NOTE: records can't be written while iterating, so they are collected first
+	all, records := []packetKey{}, []PacketRecord{}
+	itr, err := dbm.IteratePrefix(s.db, channelPrefix(c))
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	for ; itr.Valid(); itr.Next() {
+		var pr PacketRecord
+		k, err := parsePacketKey(itr.Key())
+		if err == nil {
+			err = json.Unmarshal(itr.Value(), &pr)
+		}
+		if err != nil {
+			c.Error(fmt.Errorf("invalid record for %s: %w", itr.Key(), err))
+			continue
+		}
+		all, records = append(all, k), append(records, pr)
+	}
+	itr.Close()
+
+	for i, k := range all {
+		pr := records[i]
+		switch {
+		case pr.final() && time.Since(pr.Updated) > storeRetention:
+			err = s.db.DeleteSync(k.bytes())
+		case pr.State != PacketSubmitted:
+			continue
+		case pr.TxHash == "":
+			err = s.db.DeleteSync(k.bytes())
+			released++
+		default:
+			keys, hashes = append(keys, k), append(hashes, pr.TxHash)
+		}
+		if err != nil {
+			return nil, nil, 0, err
+		}
+	}
+	return keys, hashes, released, nil
+}
+
+// resolve marks the submitted packets whose txs were found with the txs' results. Records
+// that have changed since they were read, e.g. because the packet was submitted again, are
+// left alone.
+func (s *RelayStore) resolve(keys []packetKey, hashes []string, txs map[string]sdk.TxResponse) (resolved int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, k := range keys {
+		res, ok := txs[hashes[i]]
+		if !ok {
+			continue
+		}
+		pr, err := s.get(k)
+		switch {
+		case err != nil:
+			return resolved, err
+		case pr == nil || pr.State != PacketSubmitted || pr.TxHash != hashes[i]:
+			continue
+		case res.Code != 0:
+			err = s.db.DeleteSync(k.bytes())
+		case k.msgType == packetMsgTimeout:
+			err = s.set(k, PacketTimedOut, pr.TxHash)
+		default:
+			err = s.set(k, PacketConfirmed, pr.TxHash)
+		}
+		if err != nil {
+			return resolved, err
+		}
+		resolved++
+	}
+	return resolved, nil
+}
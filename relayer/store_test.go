@@ -0,0 +1,210 @@
+package relayer
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	chanTypes "github.com/cosmos/cosmos-sdk/x/ibc/04-channel/types"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/libs/log"
+	dbm "github.com/tendermint/tm-db"
+)
+
+func testStoreChain() *Chain {
+	return &Chain{
+		ChainID: "ibc0",
+		PathEnd: &PathEnd{ChainID: "ibc0", PortID: "transfer", ChannelID: "ibczerochan"},
+		logger:  log.NewNopLogger(),
+	}
+}
+
+func testRecvMsg(c *Chain, seq uint64) sdk.Msg {
+	return chanTypes.MsgPacket{Packet: chanTypes.Packet{
+		Sequence:           seq,
+		SourcePort:         "transfer",
+		SourceChannel:      "ibconechan",
+		DestinationPort:    c.PathEnd.PortID,
+		DestinationChannel: c.PathEnd.ChannelID,
+	}}
+}
+
+func testPacketState(t *testing.T, s *RelayStore, c *Chain, msg sdk.Msg) PacketState {
+	k, ok := msgPacketKey(c, msg)
+	require.True(t, ok)
+	pr, err := s.get(k)
+	require.NoError(t, err)
+	if pr == nil {
+		return ""
+	}
+	return pr.State
+}
+
+func TestRelayStoreClaim(t *testing.T) {
+	var (
+		c          = testStoreChain()
+		one, two   = testRecvMsg(c, 1), testRecvMsg(c, 2)
+		update     = NewMsgSendPacket(chanTypes.Packet{}, nil)
+		inFlight   = func(s *RelayStore) { s.claim(c, []sdk.Msg{one}) }
+		expiredSub = func(s *RelayStore) {
+			k, _ := msgPacketKey(c, one)
+			bz, _ := json.Marshal(&PacketRecord{State: PacketSubmitted, Updated: time.Now().Add(-storeSubmitTimeout * 2)})
+			require.NoError(t, s.db.SetSync(k.bytes(), bz))
+		}
+	)
+
+	cases := []struct {
+		name     string
+		setup    func(s *RelayStore)
+		msgs     []sdk.Msg
+		expected []sdk.Msg
+		ok       bool
+	}{
+		{"new packets", func(*RelayStore) {}, []sdk.Msg{update, one, two}, []sdk.Msg{update, one, two}, true},
+		{"in flight", inFlight, []sdk.Msg{update, one}, []sdk.Msg{update}, false},
+		{"some in flight", inFlight, []sdk.Msg{update, one, two}, []sdk.Msg{update, two}, true},
+		{"released", func(s *RelayStore) {
+			s.claim(c, []sdk.Msg{one})
+			s.released(c, []sdk.Msg{one})
+		}, []sdk.Msg{one}, []sdk.Msg{one}, true},
+		{"tx failed", func(s *RelayStore) {
+			s.claim(c, []sdk.Msg{one})
+			s.submitted(c, "HASH", []sdk.Msg{one})
+			s.included(c, []sdk.Msg{one}, sdk.TxResponse{TxHash: "HASH", Code: 5}, nil)
+		}, []sdk.Msg{one}, []sdk.Msg{one}, true},
+		{"confirmed", func(s *RelayStore) {
+			s.claim(c, []sdk.Msg{one})
+			s.submitted(c, "HASH", []sdk.Msg{one})
+			s.included(c, []sdk.Msg{one}, sdk.TxResponse{TxHash: "HASH"}, nil)
+		}, []sdk.Msg{one}, nil, false},
+		{"submitted too long ago", expiredSub, []sdk.Msg{one}, []sdk.Msg{one}, true},
+		{"no packets", func(*RelayStore) {}, []sdk.Msg{update}, []sdk.Msg{update}, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &RelayStore{db: dbm.NewMemDB()}
+			tc.setup(s)
+			out, ok := s.claim(c, tc.msgs)
+			require.Equal(t, tc.expected, out)
+			require.Equal(t, tc.ok, ok)
+		})
+	}
+}
+
+func TestRelayStoreRoundTrip(t *testing.T) {
+	var (
+		c   = testStoreChain()
+		msg = testRecvMsg(c, 1)
+		s   = &RelayStore{db: dbm.NewMemDB()}
+	)
+
+	_, ok := s.claim(c, []sdk.Msg{msg})
+	require.True(t, ok)
+	require.Equal(t, PacketSubmitted, testPacketState(t, s, c, msg))
+
+	s.released(c, []sdk.Msg{msg})
+	require.Equal(t, PacketState(""), testPacketState(t, s, c, msg))
+
+	_, ok = s.claim(c, []sdk.Msg{msg})
+	require.True(t, ok)
+	s.submitted(c, "HASH", []sdk.Msg{msg})
+	s.included(c, []sdk.Msg{msg}, sdk.TxResponse{TxHash: "HASH"}, nil)
+	require.Equal(t, PacketConfirmed, testPacketState(t, s, c, msg))
+
+	// a nil store records nothing and lets every msg through
+	var nilStore *RelayStore
+	out, ok := nilStore.claim(c, []sdk.Msg{msg})
+	require.True(t, ok)
+	require.Equal(t, []sdk.Msg{msg}, out)
+}
+
+func TestRelayStoreResume(t *testing.T) {
+	c := testStoreChain()
+	cases := []struct {
+		name     string
+		record   PacketRecord
+		expected PacketState
+	}{
+		{"submitted without a tx", PacketRecord{State: PacketSubmitted, Updated: time.Now()}, ""},
+		{"seen", PacketRecord{State: PacketSeen, Updated: time.Now()}, PacketSeen},
+		{"confirmed", PacketRecord{State: PacketConfirmed, TxHash: "HASH", Updated: time.Now()}, PacketConfirmed},
+		{"old confirmed", PacketRecord{State: PacketConfirmed, TxHash: "HASH", Updated: time.Now().Add(-storeRetention * 2)}, ""},
+		{"old timed out", PacketRecord{State: PacketTimedOut, TxHash: "HASH", Updated: time.Now().Add(-storeRetention * 2)}, ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &RelayStore{db: dbm.NewMemDB()}
+			msg := testRecvMsg(c, 1)
+			k, _ := msgPacketKey(c, msg)
+			bz, err := json.Marshal(&tc.record)
+			require.NoError(t, err)
+			require.NoError(t, s.db.SetSync(k.bytes(), bz))
+
+			require.NoError(t, s.resume(context.Background(), c))
+			require.Equal(t, tc.expected, testPacketState(t, s, c, msg))
+		})
+	}
+}
+
+func TestParsePacketKey(t *testing.T) {
+	k := packetKey{"ibc0", "transfer", "ibczerochan", packetMsgAck, 42}
+	parsed, err := parsePacketKey(k.bytes())
+	require.NoError(t, err)
+	require.Equal(t, k, parsed)
+
+	_, err = parsePacketKey([]byte(packetPrefix + "ibc0/transfer"))
+	require.Error(t, err)
+}
+
+func TestRelayStoreResolve(t *testing.T) {
+	var (
+		c       = testStoreChain()
+		recv    = packetKey{c.ChainID, "transfer", "ibczerochan", packetMsgRecv, 1}
+		timeout = packetKey{c.ChainID, "transfer", "ibczerochan", packetMsgTimeout, 1}
+	)
+
+	cases := []struct {
+		name     string
+		key      packetKey
+		record   PacketRecord
+		txs      map[string]sdk.TxResponse
+		expected PacketState
+		resolved int
+	}{
+		{"confirmed", recv, PacketRecord{State: PacketSubmitted, TxHash: "HASH"},
+			map[string]sdk.TxResponse{"HASH": {TxHash: "HASH"}}, PacketConfirmed, 1},
+		{"timed out", timeout, PacketRecord{State: PacketSubmitted, TxHash: "HASH"},
+			map[string]sdk.TxResponse{"HASH": {TxHash: "HASH"}}, PacketTimedOut, 1},
+		{"tx failed", recv, PacketRecord{State: PacketSubmitted, TxHash: "HASH"},
+			map[string]sdk.TxResponse{"HASH": {TxHash: "HASH", Code: 5}}, "", 1},
+		{"tx not found", recv, PacketRecord{State: PacketSubmitted, TxHash: "HASH"},
+			map[string]sdk.TxResponse{}, PacketSubmitted, 0},
+		{"submitted again", recv, PacketRecord{State: PacketSubmitted, TxHash: "OTHER"},
+			map[string]sdk.TxResponse{"HASH": {TxHash: "HASH"}}, PacketSubmitted, 0},
+		{"confirmed since", recv, PacketRecord{State: PacketConfirmed, TxHash: "HASH"},
+			map[string]sdk.TxResponse{"HASH": {TxHash: "HASH", Code: 5}}, PacketConfirmed, 0},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &RelayStore{db: dbm.NewMemDB()}
+			require.NoError(t, s.set(tc.key, tc.record.State, tc.record.TxHash))
+
+			resolved, err := s.resolve([]packetKey{tc.key}, []string{"HASH"}, tc.txs)
+			require.NoError(t, err)
+			require.Equal(t, tc.resolved, resolved)
+
+			pr, err := s.get(tc.key)
+			require.NoError(t, err)
+			if tc.expected == "" {
+				require.Nil(t, pr)
+				return
+			}
+			require.Equal(t, tc.expected, pr.State)
+		})
+	}
+}
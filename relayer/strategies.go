@@ -3,8 +3,10 @@ package relayer
 import (
 	"context"
 	"fmt"
-
-	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
 )
 
 var (
@@ -15,11 +17,13 @@ var (
 // Strategy defines
 type Strategy interface {
 	GetType() string
-	HandleEvents(src, dst *Chain, sh *SyncHeaders, events map[string][]string)
-	UnrelayedSequencesUnordered(src, dst *Chain, sh *SyncHeaders) (*RelaySequences, error)
-	UnrelayedSequencesOrdered(src, dst *Chain, sh *SyncHeaders) (*RelaySequences, error)
-	RelayPacketsOrderedChan(src, dst *Chain, sp *RelaySequences, sh *SyncHeaders) error
-	RelayPacketsUnorderedChan(src, dst *Chain, sp *RelaySequences, sh *SyncHeaders) error
+	HandleEvents(ctx context.Context, src, dst *Chain, sh *SyncHeaders, events map[string][]string)
+	UnrelayedSequencesUnordered(ctx context.Context, src, dst *Chain, sh *SyncHeaders) (*RelaySequences, error)
+	UnrelayedSequencesOrdered(ctx context.Context, src, dst *Chain, sh *SyncHeaders) (*RelaySequences, error)
+	RelayPacketsOrderedChan(ctx context.Context, src, dst *Chain, sp *RelaySequences, sh *SyncHeaders) error
+	RelayPacketsUnorderedChan(ctx context.Context, src, dst *Chain, sp *RelaySequences, sh *SyncHeaders) error
+	UnrelayedAcknowledgements(ctx context.Context, src, dst *Chain, sh *SyncHeaders) (*RelaySequences, error)
+	RelayAcknowledgements(ctx context.Context, src, dst *Chain, sp *RelaySequences, sh *SyncHeaders) error
 }
 
 // MustGetStrategy returns the strategy and panics on error
@@ -31,138 +35,369 @@ func (r *Path) MustGetStrategy() Strategy {
 	return strat
 }
 
-// GetStrategy the strategy defined in the relay messages
+// GetStrategy returns the strategy configured for the path, built by the
+// constructor registered for the strategy type
 func (r *Path) GetStrategy() (Strategy, error) {
-	switch r.Strategy.Type {
-	case (&NaiveStrategy{}).GetType():
-		return &NaiveStrategy{}, nil
-	default:
-		return nil, fmt.Errorf("invalid strategy: %s", r.Strategy.Type)
+	if r.Strategy == nil {
+		return nil, fmt.Errorf("no strategy configured")
+	}
+
+	strategiesMu.RLock()
+	ctor, ok := strategies[r.Strategy.Type]
+	strategiesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("invalid strategy: %s, must be one of %v", r.Strategy.Type, RegisteredStrategies())
+	}
+
+	strat, err := ctor(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s strategy config: %w", r.Strategy.Type, err)
+	}
+	return strat, nil
+}
+
+// StrategyConstructor builds a Strategy to relay over the given path. It should validate
+// the params in the path's StrategyCfg and return an error if they are invalid.
+type StrategyConstructor func(path *Path) (Strategy, error)
+
+var (
+	strategiesMu sync.RWMutex
+	strategies   = make(map[string]StrategyConstructor)
+)
+
+// RegisterStrategy makes a strategy available to paths by the given type name, it
+// is meant to be called from an init function and panics if the name is already taken
+func RegisterStrategy(name string, ctor StrategyConstructor) {
+	strategiesMu.Lock()
+	defer strategiesMu.Unlock()
+
+	if name == "" || ctor == nil {
+		panic("relayer: RegisterStrategy requires a name and constructor")
 	}
+	if _, dup := strategies[name]; dup {
+		panic(fmt.Sprintf("relayer: RegisterStrategy called twice for strategy %s", name))
+	}
+	strategies[name] = ctor
+}
+
+// RegisteredStrategies returns the sorted names of the registered strategies
+func RegisteredStrategies() []string {
+	strategiesMu.RLock()
+	defer strategiesMu.RUnlock()
+
+	out := make([]string, 0, len(strategies))
+	for name := range strategies {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
 }
 
 // StrategyCfg defines which relaying strategy to take for a given path
 type StrategyCfg struct {
-	Type string `json:"type" yaml:"type"`
+	Type   string            `json:"type" yaml:"type"`
+	Params map[string]string `json:"params,omitempty" yaml:"params,omitempty"`
 }
 
-// RunStrategy runs a given strategy
-func RunStrategy(src, dst *Chain, strategy Strategy, ordered bool) (func(), error) {
-	doneChan := make(chan struct{})
+// CheckParams returns an error if any of the params set aren't in the known keys
+func (sc *StrategyCfg) CheckParams(known ...string) error {
+	for key := range sc.Params {
+		found := false
+		for _, k := range known {
+			if key == k {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("unknown param %s, must be one of %v", key, known)
+		}
+	}
+	return nil
+}
+
+// StringParam returns the param for key or def if it isn't set
+func (sc *StrategyCfg) StringParam(key, def string) string {
+	if val, ok := sc.Params[key]; ok {
+		return val
+	}
+	return def
+}
+
+// IntParam returns the param for key parsed as an int or def if it isn't set
+func (sc *StrategyCfg) IntParam(key string, def int) (int, error) {
+	val, ok := sc.Params[key]
+	if !ok {
+		return def, nil
+	}
+	out, err := strconv.Atoi(val)
+	if err != nil {
+		return 0, fmt.Errorf("param %s: %w", key, err)
+	}
+	return out, nil
+}
+
+// BoolParam returns the param for key parsed as a bool or def if it isn't set
+func (sc *StrategyCfg) BoolParam(key string, def bool) (bool, error) {
+	val, ok := sc.Params[key]
+	if !ok {
+		return def, nil
+	}
+	out, err := strconv.ParseBool(val)
+	if err != nil {
+		return false, fmt.Errorf("param %s: %w", key, err)
+	}
+	return out, nil
+}
+
+// DurationParam returns the param for key parsed as a time.Duration or def if it isn't set
+func (sc *StrategyCfg) DurationParam(key string, def time.Duration) (time.Duration, error) {
+	val, ok := sc.Params[key]
+	if !ok {
+		return def, nil
+	}
+	out, err := time.ParseDuration(val)
+	if err != nil {
+		return 0, fmt.Errorf("param %s: %w", key, err)
+	}
+	return out, nil
+}
+
+// RunStrategy runs a given strategy until ctx is done or the returned func is called,
+// reconciling the path every reconcileInterval to relay any packets the events missed.
+// A zero interval disables reconciling.
+func RunStrategy(ctx context.Context, src, dst *Chain, strategy Strategy, ordered bool, reconcileInterval time.Duration) (func(), error) {
+	stop, _, err := runStrategy(ctx, src, dst, strategy, ordered, reconcileInterval)
+	return stop, err
+}
+
+// runStrategy starts the listen loop for the given strategy and relays any
+// outstanding packets. It returns a function that stops the listen loop and
+// waits for it to exit, and a channel that receives the error if the loop
+// exits on its own. Everything started here stops once ctx is done.
+func runStrategy(ctx context.Context, src, dst *Chain, strategy Strategy, ordered bool, reconcileInterval time.Duration) (func(), <-chan error, error) {
+	var (
+		errChan = make(chan error, 1)
+		wg      sync.WaitGroup
+	)
 
 	// Fetch latest headers for each chain and store them in sync headers
-	sh, err := NewSyncHeaders(src, dst)
+	sh, err := NewSyncHeaders(ctx, src, dst)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
+	// Resolve the packets left in flight by a previous run before looking for unrelayed ones
+	if err = src.store.resume(ctx, src); err != nil {
+		return nil, nil, err
+	}
+	if err = dst.store.resume(ctx, dst); err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	hs := newHandshakeRelayer(src, dst, ordered)
+
 	// Next start the goroutine that listens to each chain for block and tx events
-	go relayerListenLoop(src, dst, doneChan, sh, strategy)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := relayerListenLoop(ctx, src, dst, sh, strategy, hs, ordered); err != nil {
+			errChan <- err
+		}
+	}()
 
-	// Fetch any unrelayed sequences depending on the channel order
-	var sp *RelaySequences
-	if ordered {
-		sp, err = strategy.UnrelayedSequencesOrdered(src, dst, sh)
-	} else {
-		sp, err = strategy.UnrelayedSequencesUnordered(src, dst, sh)
+	// stop is safe to call more than once and blocks until the listen loop has returned
+	stop := func() {
+		cancel()
+		wg.Wait()
 	}
 
-	if err != nil {
-		return nil, err
+	// Complete any handshake that was left halfway while the relayer wasn't running
+	hs.converge(ctx)
+
+	// Relay any packets that remain to be relayed
+	if err = RelayUnrelayedPackets(ctx, src, dst, sh, strategy, ordered); err != nil {
+		stop()
+		return nil, nil, err
 	}
 
-	// Relay any packets that remain to be relayed depending on order
-	if ordered {
-		err = strategy.RelayPacketsOrderedChan(src, dst, sp, sh)
-	} else {
-		err = strategy.RelayPacketsUnorderedChan(src, dst, sp, sh)
+	// Relay any acknowledgements that remain to be relayed
+	if err = RelayUnrelayedAcks(ctx, src, dst, sh, strategy); err != nil {
+		stop()
+		return nil, nil, err
 	}
 
-	if err != nil {
-		return nil, err
+	// Finally start reconciling the path for any packets missed by the listen loop
+	if reconcileInterval > 0 {
+		rec := &reconciler{src: src, dst: dst, sh: sh, strategy: strategy, handshakes: hs, ordered: ordered}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rec.run(ctx, reconcileInterval)
+		}()
 	}
 
-	// Return a function to stop the relayer goroutine
-	return func() { doneChan <- struct{}{} }, nil
+	return stop, errChan, nil
 }
 
-func relayerListenLoop(src, dst *Chain, doneChan chan struct{}, sh *SyncHeaders, strategy Strategy) {
-	var (
-		srcTxEvents, srcBlockEvents, dstTxEvents, dstBlockEvents <-chan ctypes.ResultEvent
-		srcTxCancel, srcBlockCancel, dstTxCancel, dstBlockCancel context.CancelFunc
-		err                                                      error
-	)
+// UnrelayedSequencesForStrategy returns the unrelayed sequences on both chains
+// using the strategy's method for the channel order
+func UnrelayedSequencesForStrategy(ctx context.Context, src, dst *Chain, sh *SyncHeaders, strategy Strategy, ordered bool) (*RelaySequences, error) {
+	if ordered {
+		return strategy.UnrelayedSequencesOrdered(ctx, src, dst, sh)
+	}
+	return strategy.UnrelayedSequencesUnordered(ctx, src, dst, sh)
+}
 
-	// Start client for source chain
-	if err = src.Start(); err != nil {
-		src.Error(err)
-		return
+// RelayUnrelayedPackets fetches the unrelayed sequences on both chains and relays them
+// using the strategy's method for the channel order
+// CONTRACT: the SyncHeaders passed in here must be up to date or being kept updated
+func RelayUnrelayedPackets(ctx context.Context, src, dst *Chain, sh *SyncHeaders, strategy Strategy, ordered bool) error {
+	sp, err := UnrelayedSequencesForStrategy(ctx, src, dst, sh, strategy, ordered)
+	if err != nil {
+		return err
 	}
+	return relaySequences(ctx, src, dst, sp, sh, strategy, ordered)
+}
 
-	// Subscibe to txEvents from the source chain
-	if srcTxEvents, srcTxCancel, err = src.Subscribe(txEvents); err != nil {
-		src.Error(err)
-		return
+// relaySequences relays the packets with the given sequences using the strategy's method for the channel order
+func relaySequences(ctx context.Context, src, dst *Chain, sp *RelaySequences, sh *SyncHeaders, strategy Strategy, ordered bool) error {
+	if ordered {
+		return strategy.RelayPacketsOrderedChan(ctx, src, dst, sp, sh)
 	}
-	defer srcTxCancel()
-	src.Log(fmt.Sprintf("- listening to tx events from %s...", src.ChainID))
+	return strategy.RelayPacketsUnorderedChan(ctx, src, dst, sp, sh)
+}
 
-	// Subscibe to blockEvents from the source chain
-	if srcBlockEvents, srcBlockCancel, err = src.Subscribe(blEvents); err != nil {
-		src.Error(err)
-		return
+// RelayUnrelayedAcks fetches the acknowledgements on both chains that have not
+// been relayed back to the sending chain and relays them using the strategy
+// CONTRACT: the SyncHeaders passed in here must be up to date or being kept updated
+func RelayUnrelayedAcks(ctx context.Context, src, dst *Chain, sh *SyncHeaders, strategy Strategy) error {
+	sp, err := strategy.UnrelayedAcknowledgements(ctx, src, dst, sh)
+	if err != nil {
+		return err
 	}
-	defer srcBlockCancel()
-	src.Log(fmt.Sprintf("- listening to block events from %s...", src.ChainID))
+	return strategy.RelayAcknowledgements(ctx, src, dst, sp, sh)
+}
 
-	// Subscribe to destination chain
-	if err = dst.Start(); err != nil {
-		dst.Error(err)
-		return
+// relayerListenLoop relays the packets in the events from both chains until ctx is
+// done, and completes the connection and channel handshake steps in them with hs.
+// If either chain stops producing blocks its connection is rebuilt and the path
+// is reconciled to pick up any packets that were sent while the relayer wasn't listening.
+// If a witness has a header that conflicts with either chain the loop returns the
+// ErrConflictingHeaders.
+func relayerListenLoop(ctx context.Context, src, dst *Chain, sh *SyncHeaders, strategy Strategy, hs *handshakeRelayer, ordered bool) error {
+	// Subscribe to events from the source chain
+	srcSub, err := src.subscribeEvents(ctx)
+	if err != nil {
+		src.Error(err)
+		return err
 	}
+	defer func() {
+		if srcSub != nil {
+			srcSub.close()
+		}
+	}()
+	src.Log(fmt.Sprintf("- listening to tx and block events from %s...", src.ChainID))
 
-	// Subscibe to txEvents from the destination chain
-	if dstTxEvents, dstTxCancel, err = dst.Subscribe(txEvents); err != nil {
+	// Subscribe to events from the destination chain
+	dstSub, err := dst.subscribeEvents(ctx)
+	if err != nil {
 		dst.Error(err)
-		return
+		return err
 	}
-	defer dstTxCancel()
-	dst.Log(fmt.Sprintf("- listening to tx events from %s...", dst.ChainID))
+	defer func() {
+		if dstSub != nil {
+			dstSub.close()
+		}
+	}()
+	dst.Log(fmt.Sprintf("- listening to tx and block events from %s...", dst.ChainID))
 
-	// Subscibe to blockEvents from the destination chain
-	if dstBlockEvents, dstBlockCancel, err = dst.Subscribe(blEvents); err != nil {
-		src.Error(err)
-		return
+	// resubscribe rebuilds the subscription, returning false if
+	// the relayer is shut down before it succeeds
+	resubscribe := func(sub **eventSubscription) bool {
+		var ok bool
+		if *sub, ok = (*sub).resubscribe(ctx); !ok {
+			return false
+		}
+		go reconcile(ctx, src, dst, sh, strategy, ordered)
+		go hs.converge(ctx)
+		return true
 	}
-	defer dstBlockCancel()
-	dst.Log(fmt.Sprintf("- listening to block events from %s...", dst.ChainID))
+
+	stallTicker := time.NewTicker(listenStallTimeout / 4)
+	defer stallTicker.Stop()
 
 	// Listen to channels and take appropriate action
 	for {
 		select {
-		case srcMsg := <-srcTxEvents:
+		case srcMsg, ok := <-srcSub.txs:
+			if !ok {
+				if !resubscribe(&srcSub) {
+					return nil
+				}
+				continue
+			}
 			src.logTx(srcMsg.Events)
-			go strategy.HandleEvents(dst, src, sh, srcMsg.Events)
-		case dstMsg := <-dstTxEvents:
+			go strategy.HandleEvents(ctx, dst, src, sh, srcMsg.Events)
+			go hs.handleEvents(ctx, src, srcMsg.Events)
+		case dstMsg, ok := <-dstSub.txs:
+			if !ok {
+				if !resubscribe(&dstSub) {
+					return nil
+				}
+				continue
+			}
 			dst.logTx(dstMsg.Events)
-			go strategy.HandleEvents(src, dst, sh, dstMsg.Events)
-		case srcMsg := <-srcBlockEvents:
+			go strategy.HandleEvents(ctx, src, dst, sh, dstMsg.Events)
+			go hs.handleEvents(ctx, dst, dstMsg.Events)
+		case srcMsg, ok := <-srcSub.blocks:
+			if !ok {
+				if !resubscribe(&srcSub) {
+					return nil
+				}
+				continue
+			}
 			// TODO: Add debug block logging here
-			if err = sh.Update(src); err != nil {
+			srcSub.lastBlock = time.Now()
+			if err = sh.Update(ctx, src); err != nil {
+				if _, ok := IsConflictingHeaders(err); ok {
+					return err
+				}
 				src.Error(err)
 			}
-			go strategy.HandleEvents(dst, src, sh, srcMsg.Events)
-		case dstMsg := <-dstBlockEvents:
+			go strategy.HandleEvents(ctx, dst, src, sh, srcMsg.Events)
+		case dstMsg, ok := <-dstSub.blocks:
+			if !ok {
+				if !resubscribe(&dstSub) {
+					return nil
+				}
+				continue
+			}
 			// TODO: Add debug block logging here
-			if err = sh.Update(dst); err != nil {
+			dstSub.lastBlock = time.Now()
+			if err = sh.Update(ctx, dst); err != nil {
+				if _, ok := IsConflictingHeaders(err); ok {
+					return err
+				}
 				dst.Error(err)
 			}
-			go strategy.HandleEvents(src, dst, sh, dstMsg.Events)
-		case <-doneChan:
+			go strategy.HandleEvents(ctx, src, dst, sh, dstMsg.Events)
+		case <-stallTicker.C:
+			for _, sub := range []**eventSubscription{&srcSub, &dstSub} {
+				if !(*sub).stalled() {
+					continue
+				}
+				c := (*sub).chain
+				c.Log(fmt.Sprintf("- no blocks from %s in %s, reconnecting...", c.ChainID, listenStallTimeout))
+				if !resubscribe(sub) {
+					return nil
+				}
+			}
+		case <-ctx.Done():
 			src.Log(fmt.Sprintf("- [%s]:{%s} <-> [%s]:{%s} relayer shutting down",
 				src.ChainID, src.PathEnd.PortID, dst.ChainID, dst.PathEnd.PortID))
-			close(doneChan)
-			return
+			return nil
 		}
 	}
 }
@@ -0,0 +1,227 @@
+package relayer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// defaultClientRefresh is used for paths that don't set client-refresh
+	defaultClientRefresh = 2.0 / 3.0
+
+	// clientRefreshInterval is how often the supervisor checks whether clients need updating
+	clientRefreshInterval = time.Minute
+)
+
+// PathSupervisor runs the configured strategy for a set of paths from a
+// single process, restarting the relayer for any path that fails
+type PathSupervisor struct {
+	chains       Chains
+	paths        map[string]*supervisedPath
+	restartDelay time.Duration
+	store        *RelayStore
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewPathSupervisor returns a PathSupervisor for the given chains that waits
+// restartDelay before restarting a failed path
+func NewPathSupervisor(chains Chains, restartDelay time.Duration) *PathSupervisor {
+	return &PathSupervisor{
+		chains:       chains,
+		paths:        make(map[string]*supervisedPath),
+		restartDelay: restartDelay,
+	}
+}
+
+// supervisedPath holds the path and the chains used to relay over it
+type supervisedPath struct {
+	path     *Path
+	src, dst *Chain
+
+	// cancel stops relaying over just this path
+	cancel context.CancelFunc
+}
+
+// Add registers a path with the supervisor, it must be called before Start
+func (ps *PathSupervisor) Add(name string, path *Path) error {
+	if _, ok := ps.paths[name]; ok {
+		return fmt.Errorf("path %s has already been added", name)
+	}
+	if err := path.Validate(); err != nil {
+		return fmt.Errorf("path %s: %w", name, err)
+	}
+	src, dst, err := ps.pathChains(path)
+	if err != nil {
+		return fmt.Errorf("path %s: %w", name, err)
+	}
+	ps.paths[name] = &supervisedPath{path: path, src: src, dst: dst}
+	return nil
+}
+
+// UseStore records the relay state of the packets on every path in the store, so that
+// packets already being relayed are skipped and relaying resumes cleanly after a restart
+func (ps *PathSupervisor) UseStore(store *RelayStore) {
+	ps.store = store
+	for _, sp := range ps.paths {
+		sp.src.store, sp.dst.store = store, store
+	}
+}
+
+// Paths returns the sorted names of the paths being supervised
+func (ps *PathSupervisor) Paths() []string {
+	out := make([]string, 0, len(ps.paths))
+	for name := range ps.paths {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Start runs the relayer for each path in its own goroutine until ctx is done or Stop is called
+func (ps *PathSupervisor) Start(ctx context.Context) error {
+	if len(ps.paths) == 0 {
+		return fmt.Errorf("no paths to relay")
+	}
+
+	ctx, ps.cancel = context.WithCancel(ctx)
+	for _, name := range ps.Paths() {
+		sp := ps.paths[name]
+		var pathCtx context.Context
+		pathCtx, sp.cancel = context.WithCancel(ctx)
+		ps.wg.Add(1)
+		go ps.supervise(pathCtx, name, sp)
+	}
+	return nil
+}
+
+// StopPath shuts down the relayer for a single path, leaving the others running
+func (ps *PathSupervisor) StopPath(name string) error {
+	sp, ok := ps.paths[name]
+	if !ok || sp.cancel == nil {
+		return fmt.Errorf("path %s isn't being relayed", name)
+	}
+	sp.cancel()
+	return nil
+}
+
+// Stop shuts down the relayer for every path and waits for them to exit
+func (ps *PathSupervisor) Stop() {
+	if ps.cancel != nil {
+		ps.cancel()
+	}
+	ps.wg.Wait()
+}
+
+// supervise runs the relayer for a single path until ctx is done
+func (ps *PathSupervisor) supervise(ctx context.Context, name string, sp *supervisedPath) {
+	defer ps.wg.Done()
+	for {
+		err := ps.run(ctx, sp.src, sp.dst, sp.path)
+		if err == nil || ctx.Err() != nil {
+			return
+		}
+
+		// a chain on the path has forked, so it isn't safe to keep relaying
+		if conflict, ok := IsConflictingHeaders(err); ok {
+			ps.halt(ctx, name, sp, conflict)
+			return
+		}
+
+		sp.src.Log(fmt.Sprintf("- path %s failed: %s, restarting in %s", name, err, ps.restartDelay))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(ps.restartDelay):
+		}
+	}
+}
+
+// halt stops relaying over a path after a chain on it has forked, and freezes
+// the client for the forked chain on the counterparty chain
+func (ps *PathSupervisor) halt(ctx context.Context, name string, sp *supervisedPath, conflict *ErrConflictingHeaders) {
+	forked, counterparty := sp.src, sp.dst
+	if conflict.ChainID == sp.dst.ChainID {
+		forked, counterparty = sp.dst, sp.src
+	}
+
+	forked.Error(fmt.Errorf("path %s halted: %w", name, conflict))
+	if err := forked.SubmitMisbehaviour(ctx, counterparty, conflict); err != nil {
+		counterparty.Error(err)
+	}
+}
+
+// run relays over the path and keeps its clients from expiring, it blocks
+// until either the relayer fails or ctx is done
+func (ps *PathSupervisor) run(ctx context.Context, src, dst *Chain, path *Path) error {
+	strategy, err := path.GetStrategy()
+	if err != nil {
+		return err
+	}
+
+	stop, errs, err := runStrategy(ctx, src, dst, strategy, path.Ordered(), path.GetReconcileInterval())
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	refreshCtx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		refreshClients(refreshCtx, src, dst, path.GetClientRefresh())
+	}()
+	defer wg.Wait()
+	defer cancel()
+
+	select {
+	case err = <-errs:
+		return err
+	case <-ctx.Done():
+		return nil
+	}
+}
+
+// refreshClients checks the path's clients every clientRefreshInterval until ctx
+// is done, updating them once threshold of their trusting period has passed
+func refreshClients(ctx context.Context, src, dst *Chain, threshold float64) {
+	ticker := time.NewTicker(clientRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := RefreshClients(ctx, src, dst, threshold); err != nil {
+			src.Error(fmt.Errorf("failed to refresh clients: %w", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// pathChains returns copies of the chains for a path with the path ends set. Copies
+// are used so that paths sharing a chain don't overwrite each other's PathEnd,
+// the underlying rpc client and keybase are still shared.
+func (ps *PathSupervisor) pathChains(path *Path) (*Chain, *Chain, error) {
+	chains, err := ps.chains.Gets(path.Src.ChainID, path.Dst.ChainID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	src, dst := *chains[path.Src.ChainID], *chains[path.Dst.ChainID]
+	src.store, dst.store = ps.store, ps.store
+	if err = src.SetPath(path.Src); err != nil {
+		return nil, nil, err
+	}
+	if err = dst.SetPath(path.Dst); err != nil {
+		return nil, nil, err
+	}
+	return &src, &dst, nil
+}
@@ -1,6 +1,7 @@
 package relayer
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -16,7 +17,6 @@ import (
 	abci "github.com/tendermint/tendermint/abci/types"
 	"github.com/tendermint/tendermint/libs/log"
 	lite "github.com/tendermint/tendermint/lite2"
-	litep "github.com/tendermint/tendermint/lite2/provider"
 	litehttp "github.com/tendermint/tendermint/lite2/provider/http"
 	dbs "github.com/tendermint/tendermint/lite2/store/db"
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
@@ -37,14 +37,14 @@ func (h *header) err() error {
 }
 
 // UpdatesWithHeaders calls UpdateLiteWithHeader on the passed chains concurrently
-func UpdatesWithHeaders(chains ...*Chain) (map[string]*tmclient.Header, error) {
+func UpdatesWithHeaders(ctx context.Context, chains ...*Chain) (map[string]*tmclient.Header, error) {
 	hs := &header{Map: make(map[string]*tmclient.Header), Errs: []error{}}
 	var wg sync.WaitGroup
 	for _, chain := range chains {
 		wg.Add(1)
 		go func(hs *header, wg *sync.WaitGroup, chain *Chain) {
 			defer wg.Done()
-			header, err := chain.UpdateLiteWithHeader()
+			header, err := chain.UpdateLiteWithHeader(ctx)
 			hs.Lock()
 			hs.Map[chain.ChainID] = header
 			if err != nil {
@@ -58,7 +58,25 @@ func UpdatesWithHeaders(chains ...*Chain) (map[string]*tmclient.Header, error) {
 }
 
 // UpdateLiteWithHeader calls client.Update and then .
-func (c *Chain) UpdateLiteWithHeader() (*tmclient.Header, error) {
+func (c *Chain) UpdateLiteWithHeader(ctx context.Context) (*tmclient.Header, error) {
+	var hdr *tmclient.Header
+	err := rpcCall(ctx, func() (err error) {
+		hdr, err = c.updateLiteWithHeader()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.checkWitnesses(ctx, hdr); err != nil {
+		return nil, err
+	}
+	return hdr, nil
+}
+
+// updateLiteWithHeader updates the lite client to the latest header, the database
+// is opened and closed here so the update can be abandoned without leaking it
+func (c *Chain) updateLiteWithHeader() (*tmclient.Header, error) {
 	// create database connection
 	db, df, err := c.NewLiteDB()
 	if err != nil {
@@ -93,10 +111,8 @@ func (c *Chain) UpdateLiteWithHeader() (*tmclient.Header, error) {
 
 // LiteClientWithoutTrust reads the trusted period off of the chain.
 func (c *Chain) LiteClientWithoutTrust(db *dbm.GoLevelDB) (*lite.Client, error) {
-	httpProvider, err := litehttp.New(c.ChainID, c.RPCAddr)
-	if err != nil {
-		return nil, err
-	}
+	// the primary provider shares the chain's client so it fails over with it
+	httpProvider := litehttp.NewWithClient(c.ChainID, c.rpc)
 
 	// NOTE: currently we are discarding the very noisy lite client logs
 	// it would be nice if we could add a setting the chain or otherwise
@@ -104,18 +120,14 @@ func (c *Chain) LiteClientWithoutTrust(db *dbm.GoLevelDB) (*lite.Client, error)
 	// on the Chain struct that users could pass in the config??)
 	logger := log.NewTMLogger(log.NewSyncWriter(ioutil.Discard))
 
-	// TODO: provide actual witnesses!
 	return lite.NewClientFromTrustedStore(c.ChainID, c.GetTrustingPeriod(), httpProvider,
-		[]litep.Provider{httpProvider}, dbs.New(db, ""),
+		c.witnessProviders(httpProvider), dbs.New(db, ""),
 		lite.Logger(logger))
 }
 
 // LiteClient initializes the lite client for a given chain.
 func (c *Chain) LiteClient(db *dbm.GoLevelDB, trustOpts lite.TrustOptions) (*lite.Client, error) {
-	httpProvider, err := litehttp.New(c.ChainID, c.RPCAddr)
-	if err != nil {
-		return nil, err
-	}
+	httpProvider := litehttp.NewWithClient(c.ChainID, c.rpc)
 
 	// NOTE: currently we are discarding the very noisy lite client logs
 	// it would be nice if we could add a setting the chain or otherwise
@@ -123,9 +135,8 @@ func (c *Chain) LiteClient(db *dbm.GoLevelDB, trustOpts lite.TrustOptions) (*lit
 	// on the Chain struct that users could pass in the config??)
 	logger := log.NewTMLogger(log.NewSyncWriter(ioutil.Discard))
 
-	// TODO: provide actual witnesses!
 	return lite.NewClient(c.ChainID, trustOpts, httpProvider,
-		[]litep.Provider{httpProvider}, dbs.New(db, ""),
+		c.witnessProviders(httpProvider), dbs.New(db, ""),
 		lite.Logger(logger))
 }
 
@@ -143,7 +154,7 @@ func (c *Chain) InitLiteClient(db *dbm.GoLevelDB, trustOpts lite.TrustOptions) (
 }
 
 // TrustNodeInitClient trusts the configured node and initializes the lite client
-func (c *Chain) TrustNodeInitClient(db *dbm.GoLevelDB) (*lite.Client, error) {
+func (c *Chain) TrustNodeInitClient(ctx context.Context, db *dbm.GoLevelDB) (*lite.Client, error) {
 	// fetch latest height from configured node
 	var (
 		height int64
@@ -151,17 +162,17 @@ func (c *Chain) TrustNodeInitClient(db *dbm.GoLevelDB) (*lite.Client, error) {
 	)
 
 	if err := retry.Do(func() error {
-		height, err = c.QueryLatestHeight()
+		height, err = c.QueryLatestHeight(ctx)
 		if err != nil || height == 0 {
 			return err
 		}
 		return nil
-	}); err != nil {
+	}, retryUntilDone(ctx)); err != nil {
 		return nil, err
 	}
 
 	// fetch header from configured node
-	header, err := c.QueryHeaderAtHeight(height)
+	header, err := c.QueryHeaderAtHeight(ctx, height)
 	if err != nil {
 		return nil, err
 	}
@@ -286,12 +297,12 @@ func (c *Chain) GetLiteSignedHeaderAtHeight(height int64) (*tmclient.Header, err
 var ErrLiteNotInitialized = errors.New("lite client is not initialized")
 
 // ForceInitLite forces initialization of the lite client from the configured node
-func (c *Chain) ForceInitLite() error {
+func (c *Chain) ForceInitLite(ctx context.Context) error {
 	db, df, err := c.NewLiteDB()
 	if err != nil {
 		return err
 	}
-	_, err = c.TrustNodeInitClient(db)
+	_, err = c.TrustNodeInitClient(ctx, db)
 	if err != nil {
 		return err
 	}
@@ -1,6 +1,7 @@
 package test
 
 import (
+	"context"
 	"testing"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
@@ -33,65 +34,65 @@ func TestAgoricToGaiaStreaming(t *testing.T) {
 	require.NoError(t, err)
 
 	// query initial balances to compare against at the end
-	srcExpected, err := src.QueryBalance(src.Key)
+	srcExpected, err := src.QueryBalance(context.Background(), src.Key)
 	require.NoError(t, err)
-	dstExpected, err := dst.QueryBalance(dst.Key)
+	dstExpected, err := dst.QueryBalance(context.Background(), dst.Key)
 	require.NoError(t, err)
 
 	// create path
-	require.NoError(t, src.CreateClients(dst))
+	require.NoError(t, src.CreateClients(context.Background(), dst))
 	testClientPair(t, src, dst)
-	require.NoError(t, src.CreateConnection(dst, src.GetTimeout()))
+	require.NoError(t, src.CreateConnection(context.Background(), dst, src.GetTimeout()))
 	testConnectionPair(t, src, dst)
-	require.NoError(t, src.CreateChannel(dst, true, src.GetTimeout()))
+	require.NoError(t, src.CreateChannel(context.Background(), dst, true, src.GetTimeout()))
 	testChannelPair(t, src, dst)
 
 	// send a couple of transfers to the queue on src
-	require.NoError(t, src.SendTransferMsg(dst, srcTestCoin, dst.MustGetAddress(), true))
-	require.NoError(t, src.SendTransferMsg(dst, srcTestCoin, dst.MustGetAddress(), true))
+	require.NoError(t, src.SendTransferMsg(context.Background(), dst, srcTestCoin, dst.MustGetAddress(), true))
+	require.NoError(t, src.SendTransferMsg(context.Background(), dst, srcTestCoin, dst.MustGetAddress(), true))
 
 	// send a couple of transfers to the queue on dst
-	require.NoError(t, dst.SendTransferMsg(src, dstTestCoin, src.MustGetAddress(), true))
-	require.NoError(t, dst.SendTransferMsg(src, dstTestCoin, src.MustGetAddress(), true))
+	require.NoError(t, dst.SendTransferMsg(context.Background(), src, dstTestCoin, src.MustGetAddress(), true))
+	require.NoError(t, dst.SendTransferMsg(context.Background(), src, dstTestCoin, src.MustGetAddress(), true))
 
 	// Wait for message inclusion in both chains
-	require.NoError(t, dst.WaitForNBlocks(1))
+	require.NoError(t, dst.WaitForNBlocks(context.Background(), 1))
 
 	// start the relayer process in it's own goroutine
-	rlyDone, err := relayer.RunStrategy(src, dst, path.MustGetStrategy(), path.Ordered())
+	rlyDone, err := relayer.RunStrategy(context.Background(), src, dst, path.MustGetStrategy(), path.Ordered(), path.GetReconcileInterval())
 	require.NoError(t, err)
 
 	// send those tokens from dst back to dst and src back to src
-	require.NoError(t, src.SendTransferMsg(dst, twoDstTestCoin, dst.MustGetAddress(), false))
-	require.NoError(t, dst.SendTransferMsg(src, twoSrcTestCoin, src.MustGetAddress(), false))
+	require.NoError(t, src.SendTransferMsg(context.Background(), dst, twoDstTestCoin, dst.MustGetAddress(), false))
+	require.NoError(t, dst.SendTransferMsg(context.Background(), src, twoSrcTestCoin, src.MustGetAddress(), false))
 
 	// wait for packet processing
-	require.NoError(t, dst.WaitForNBlocks(4))
+	require.NoError(t, dst.WaitForNBlocks(context.Background(), 4))
 
 	// kill relayer routine
 	rlyDone()
 
 	// check balance on src against expected
-	srcGot, err := src.QueryBalance(src.Key)
+	srcGot, err := src.QueryBalance(context.Background(), src.Key)
 	require.NoError(t, err)
 	require.Equal(t, srcExpected.AmountOf(srcDenom).Int64(), srcGot.AmountOf(srcDenom).Int64())
 
 	// check balance on dst against expected
-	dstGot, err := dst.QueryBalance(dst.Key)
+	dstGot, err := dst.QueryBalance(context.Background(), dst.Key)
 	require.NoError(t, err)
 	require.Equal(t, dstExpected.AmountOf(dstDenom).Int64(), dstGot.AmountOf(dstDenom).Int64())
 
 	// Test the full transfer command as well
-	require.NoError(t, src.SendTransferBothSides(dst, srcTestCoin, dst.MustGetAddress(), true))
-	require.NoError(t, dst.SendTransferBothSides(src, srcTestCoin, src.MustGetAddress(), false))
+	require.NoError(t, src.SendTransferBothSides(context.Background(), dst, srcTestCoin, dst.MustGetAddress(), true))
+	require.NoError(t, dst.SendTransferBothSides(context.Background(), src, srcTestCoin, src.MustGetAddress(), false))
 
 	// check balance on src against expected
-	srcGot, err = src.QueryBalance(src.Key)
+	srcGot, err = src.QueryBalance(context.Background(), src.Key)
 	require.NoError(t, err)
 	require.Equal(t, srcExpected.AmountOf(srcDenom).Int64(), srcGot.AmountOf(srcDenom).Int64())
 
 	// check balance on dst against expected
-	dstGot, err = dst.QueryBalance(dst.Key)
+	dstGot, err = dst.QueryBalance(context.Background(), dst.Key)
 	require.NoError(t, err)
 	require.Equal(t, dstExpected.AmountOf(dstDenom).Int64(), dstGot.AmountOf(dstDenom).Int64())
 }
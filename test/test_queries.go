@@ -1,6 +1,7 @@
 package test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -18,12 +19,12 @@ func testClientPair(t *testing.T, src, dst *Chain) {
 // testClient expects just one client on src, that for dst
 // TODO: we should be able to find the chain id of dst on src, add a case for this in each switch
 func testClient(t *testing.T, src, dst *Chain) {
-	clients, err := src.QueryClients(1, 1000)
+	clients, err := src.QueryClients(context.Background(), 1, 1000)
 	require.NoError(t, err)
 	require.Equal(t, len(clients), 1)
 	require.Equal(t, clients[0].GetID(), src.PathEnd.ClientID)
 
-	client, err := src.QueryClientState()
+	client, err := src.QueryClientState(context.Background())
 	require.NoError(t, err)
 	require.NotNil(t, client)
 	require.Equal(t, client.ClientState.GetID(), src.PathEnd.ClientID)
@@ -38,7 +39,7 @@ func testConnectionPair(t *testing.T, src, dst *Chain) {
 
 // testConnection tests that the only connection on src has a counterparty that is the connection on dst
 func testConnection(t *testing.T, src, dst *Chain) {
-	conns, err := src.QueryConnections(1, 1000)
+	conns, err := src.QueryConnections(context.Background(), 1, 1000)
 	require.NoError(t, err)
 	require.Equal(t, len(conns), 1)
 	require.Equal(t, conns[0].Connection.GetClientID(), src.PathEnd.ClientID)
@@ -49,7 +50,7 @@ func testConnection(t *testing.T, src, dst *Chain) {
 	h, err := src.Client.Status()
 	require.NoError(t, err)
 
-	conn, err := src.QueryConnection(h.SyncInfo.LatestBlockHeight)
+	conn, err := src.QueryConnection(context.Background(), h.SyncInfo.LatestBlockHeight)
 	require.NoError(t, err)
 	require.Equal(t, conn.Connection.Connection.GetClientID(), src.PathEnd.ClientID)
 	require.Equal(t, conn.Connection.Connection.GetCounterparty().GetClientID(), dst.PathEnd.ClientID)
@@ -65,7 +66,7 @@ func testChannelPair(t *testing.T, src, dst *Chain) {
 
 // testChannel tests that the only channel on src is a counterparty of dst
 func testChannel(t *testing.T, src, dst *Chain) {
-	chans, err := src.QueryChannels(1, 1000)
+	chans, err := src.QueryChannels(context.Background(), 1, 1000)
 	require.NoError(t, err)
 	require.Equal(t, 1, len(chans))
 	require.Equal(t, chans[0].Channel.GetOrdering().String(), "ORDERED")
@@ -76,7 +77,7 @@ func testChannel(t *testing.T, src, dst *Chain) {
 	h, err := src.Client.Status()
 	require.NoError(t, err)
 
-	ch, err := src.QueryChannel(h.SyncInfo.LatestBlockHeight)
+	ch, err := src.QueryChannel(context.Background(), h.SyncInfo.LatestBlockHeight)
 	require.NoError(t, err)
 	require.Equal(t, ch.Channel.Channel.GetOrdering().String(), "ORDERED")
 	require.Equal(t, ch.Channel.Channel.GetState().String(), "OPEN")
@@ -1,6 +1,7 @@
 package test
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -152,14 +153,14 @@ func spinUpTestContainer(t *testing.T, rchan chan<- *dockertest.Resource, pool *
 	c.Log(fmt.Sprintf("- [%s] SPUN UP IN CONTAINER %s from %s", c.ChainID, resource.Container.Name, resource.Container.Config.Image))
 
 	// retry polling the container until status doesn't error
-	if err = pool.Retry(c.StatusErr); err != nil {
+	if err = pool.Retry(func() error { return c.StatusErr(context.Background()) }); err != nil {
 		require.NoError(t, fmt.Errorf("Could not connect to container at %s: %s", c.RPCAddr, err))
 	}
 
 	c.Log(fmt.Sprintf("- [%s] CONTAINER AVAILABLE AT PORT %s", c.ChainID, c.RPCAddr))
 
 	// initalize the lite client
-	require.NoError(t, c.ForceInitLite())
+	require.NoError(t, c.ForceInitLite(context.Background()))
 
 	rchan <- resource
 }